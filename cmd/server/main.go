@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"movie-api/internal/auth"
 	"movie-api/internal/database"
+	"movie-api/internal/handlers"
 	"movie-api/internal/routes"
+	"movie-api/pkg/utils"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -18,23 +27,92 @@ func main() {
 		log.Println("✅ .env file loaded successfully")
 	}
 
+	// Maintenance subcommands run against the database and exit
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-ratings" {
+		runRebuildRatings(os.Args[2:])
+		return
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 
+	if err := auth.InitSigningKeys(); err != nil {
+		log.Fatal("Invalid token signing keys:", err)
+	}
+	if err := auth.InitCookieConfig(); err != nil {
+		log.Fatal("Invalid user token cookie settings:", err)
+	}
+
 	// Initialize database
 	if err := database.InitDB(); err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
+
+	// Revoked tokens are checked in memory on every request
+	revoked, err := database.LoadRevokedTokens(database.DB)
+	if err != nil {
+		log.Fatal("Failed to load revoked tokens:", err)
+	}
+	auth.LoadRevokedTokens(revoked)
 	
+	// Live rating updates are fed by the vote flusher
+	ratingHub := handlers.InitRatingStream()
+
 	// Setup routes
 	router := routes.SetupRoutes()
 	routes.SetupAdminRoutes(router)
 
 	port := getEnv("PORT", "8080")
-	log.Printf("🚀 Server starting on :%s", port)
-	
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
 	}
+	// Open streams never go idle, so end them when shutdown starts
+	server.RegisterOnShutdown(ratingHub.Close)
+
+	// Kept off the public port when METRICS_ADDR is set
+	metricsServer := routes.MetricsServer()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("🚀 Server starting on :%s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	if metricsServer != nil {
+		go func() {
+			log.Printf("📈 Metrics listening on %s", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal("Failed to start metrics server:", err)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	stop()
+	log.Println("🛑 Shutdown signal received, draining connections...")
+
+	shutdownTimeout := utils.GetEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ HTTP server did not drain within %s: %v", shutdownTimeout, err)
+	}
+	if metricsServer != nil {
+		metricsServer.Shutdown(shutdownCtx)
+	}
+
+	// No more requests can enqueue votes - flush everything that is left
+	database.ResponseManagerInstance.Shutdown()
+
+	if err := database.CloseDB(); err != nil {
+		log.Printf("⚠️ Failed to close database: %v", err)
+	}
+	log.Println("👋 Server stopped")
 }
 
 func getEnv(key, defaultValue string) string {
@@ -42,4 +120,4 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
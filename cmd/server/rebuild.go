@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"movie-api/internal/database"
+	"os"
+)
+
+// runRebuildRatings - `rebuild-ratings [-slug s] [-dry-run]` subcommand
+func runRebuildRatings(args []string) {
+	flags := flag.NewFlagSet("rebuild-ratings", flag.ExitOnError)
+	slug := flags.String("slug", "", "Only rebuild this movie (default: all movies)")
+	dryRun := flags.Bool("dry-run", false, "Report drift without writing")
+	flags.Parse(args)
+
+	if err := database.OpenDB(); err != nil {
+		log.Fatal("Failed to open database:", err)
+	}
+	defer database.CloseDB()
+
+	drifts, err := database.RebuildAggregates(database.DB, *slug, *dryRun)
+	if err != nil {
+		log.Fatal("Rebuild failed:", err)
+	}
+
+	if len(drifts) == 0 {
+		fmt.Println("✅ No drift found")
+		return
+	}
+
+	fmt.Printf("%-40s %-28s %-28s\n", "MOVIE", "STORED (0/1/2/3 total)", "COMPUTED (0/1/2/3 total)")
+	for _, d := range drifts {
+		fmt.Printf("%-40s %-28s %-28s\n", d.MovieSlug, formatCounts(d.Stored), formatCounts(d.Computed))
+	}
+
+	if *dryRun {
+		fmt.Printf("🔍 Dry run: %d movies would be rebuilt\n", len(drifts))
+		return
+	}
+	fmt.Printf("🔧 Rebuilt %d movies\n", len(drifts))
+	// A running server keeps cached counts until restart; prefer POST /admin/ratings/rebuild
+	fmt.Fprintln(os.Stderr, "ℹ️ Restart a running server (or use POST /admin/ratings/rebuild) to refresh its rating cache")
+}
+
+func formatCounts(c database.MovieResponseCounts) string {
+	return fmt.Sprintf("%d/%d/%d/%d %d", c.Option0, c.Option1, c.Option2, c.Option3, c.Total)
+}
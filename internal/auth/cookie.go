@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CookieConfig - Attributes of the user token cookie
+type CookieConfig struct {
+	Name     string
+	Domain   string // empty = host-only
+	Secure   bool
+	SameSite http.SameSite
+	Lifetime time.Duration // cookie Max-Age and token expiry
+}
+
+var (
+	cookieConfig     CookieConfig
+	cookieConfigErr  error
+	cookieConfigOnce sync.Once
+)
+
+// InitCookieConfig - Load cookie attributes from the environment
+//
+//	AUTH_COOKIE_NAME      default user_token
+//	AUTH_COOKIE_DOMAIN    e.g. .example.com to share the cookie across subdomains
+//	AUTH_COOKIE_SECURE    default true when APP_ENV=production, false otherwise
+//	AUTH_COOKIE_SAMESITE  lax (default), strict or none - none needs Secure
+//	AUTH_COOKIE_LIFETIME  Go duration, default 8760h (one year)
+func InitCookieConfig() error {
+	cookieConfigOnce.Do(func() {
+		cookieConfig, cookieConfigErr = loadCookieConfig()
+	})
+	return cookieConfigErr
+}
+
+func loadCookieConfig() (CookieConfig, error) {
+	production := isProduction()
+
+	cfg := CookieConfig{
+		Name:     getEnv("AUTH_COOKIE_NAME", "user_token"),
+		Domain:   os.Getenv("AUTH_COOKIE_DOMAIN"),
+		Secure:   production,
+		SameSite: http.SameSiteLaxMode,
+		Lifetime: 365 * 24 * time.Hour,
+	}
+
+	if value := os.Getenv("AUTH_COOKIE_SECURE"); value != "" {
+		secure, err := strconv.ParseBool(value)
+		if err != nil {
+			return cfg, fmt.Errorf("AUTH_COOKIE_SECURE %q is not a boolean", value)
+		}
+		cfg.Secure = secure
+	}
+
+	switch sameSite := strings.ToLower(os.Getenv("AUTH_COOKIE_SAMESITE")); sameSite {
+	case "", "lax":
+		cfg.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cfg.SameSite = http.SameSiteStrictMode
+	case "none":
+		cfg.SameSite = http.SameSiteNoneMode
+	default:
+		return cfg, fmt.Errorf("AUTH_COOKIE_SAMESITE %q must be lax, strict or none", sameSite)
+	}
+
+	if value := os.Getenv("AUTH_COOKIE_LIFETIME"); value != "" {
+		lifetime, err := time.ParseDuration(value)
+		if err != nil || lifetime <= 0 {
+			return cfg, fmt.Errorf("AUTH_COOKIE_LIFETIME %q is not a positive duration", value)
+		}
+		cfg.Lifetime = lifetime
+	}
+
+	// Browsers drop SameSite=None cookies that aren't Secure
+	if cfg.SameSite == http.SameSiteNoneMode && !cfg.Secure {
+		return cfg, fmt.Errorf("AUTH_COOKIE_SAMESITE=none requires AUTH_COOKIE_SECURE=true")
+	}
+	if production && !cfg.Secure {
+		log.Println("⚠️ APP_ENV=production but the user token cookie is not Secure")
+	}
+
+	log.Printf("🍪 User token cookie %q (domain %q, secure %t, lifetime %s)", cfg.Name, cfg.Domain, cfg.Secure, cfg.Lifetime)
+	return cfg, nil
+}
+
+// currentCookieConfig - The cookie attributes, loaded on first use if main didn't call InitCookieConfig
+func currentCookieConfig() CookieConfig {
+	if err := InitCookieConfig(); err != nil {
+		log.Fatalf("❌ Invalid user token cookie settings: %v", err)
+	}
+	return cookieConfig
+}
+
+// newCookie - The token cookie carrying value with the configured attributes
+func (cfg CookieConfig) newCookie(value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     cfg.Name,
+		Value:    value,
+		Path:     "/",
+		Domain:   cfg.Domain,
+		MaxAge:   int(cfg.Lifetime / time.Second),
+		HttpOnly: true,
+		Secure:   cfg.Secure,
+		SameSite: cfg.SameSite,
+	}
+}
+
+// isProduction - APP_ENV=production turns on the strict defaults
+func isProduction() bool {
+	return strings.EqualFold(os.Getenv("APP_ENV"), "production")
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// signingKeys - One key signs new tokens; every listed key still verifies
+type signingKeys struct {
+	currentID string
+	keys      map[string][]byte
+	// Secret behind the old two-part tokens that carry no key ID (nil = reject them)
+	legacy []byte
+	// Legacy tokens are rejected from this moment on
+	legacyUntil time.Time
+}
+
+var (
+	keyring     *signingKeys
+	keyringErr  error
+	keyringOnce sync.Once
+)
+
+// minSecretLength - Shorter HMAC secrets are accepted with a warning
+const minSecretLength = 32
+
+// InitSigningKeys - Load token signing keys from the environment
+//
+//	AUTH_SIGNING_KEYS="2024b:<secret>,2024a:<secret>"  verification keys by ID (required when APP_ENV=production)
+//	AUTH_SIGNING_KEY_ID="2024b"                         key that signs new tokens (default: first listed)
+//	AUTH_LEGACY_KEY="<secret>"                          accept tokens issued before key IDs existed
+//	AUTH_LEGACY_UNTIL="2025-03-01T00:00:00Z"            required with AUTH_LEGACY_KEY - legacy tokens are rejected after it
+//
+// Tokens signed with any key other than the current one are re-issued on the next request,
+// so a retired key can be dropped from the list once its tokens have cycled through.
+//
+// Legacy acceptance is a temporary migration aid: the old secret was published in the repo, so
+// anyone can forge a legacy token. Keep the window short. Converted tokens keep their user ID but
+// count as brand-new tokens, both for the issuance limit and for new-token quarantine.
+func InitSigningKeys() error {
+	keyringOnce.Do(func() {
+		keyring, keyringErr = loadSigningKeys(os.Getenv("AUTH_SIGNING_KEYS"), os.Getenv("AUTH_SIGNING_KEY_ID"),
+			os.Getenv("AUTH_LEGACY_KEY"), os.Getenv("AUTH_LEGACY_UNTIL"), isProduction())
+	})
+	return keyringErr
+}
+
+func loadSigningKeys(keyList, currentID, legacy, legacyUntil string, production bool) (*signingKeys, error) {
+	ring := &signingKeys{keys: make(map[string][]byte)}
+	if legacy != "" {
+		if legacyUntil == "" {
+			return nil, fmt.Errorf("AUTH_LEGACY_KEY needs AUTH_LEGACY_UNTIL, the time legacy tokens stop being accepted")
+		}
+		until, err := time.Parse(time.RFC3339, legacyUntil)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_LEGACY_UNTIL %q is not an RFC 3339 time", legacyUntil)
+		}
+		if time.Now().After(until) {
+			log.Printf("⚠️ AUTH_LEGACY_UNTIL %s has passed - legacy tokens are rejected, AUTH_LEGACY_KEY can be removed", legacyUntil)
+		}
+		ring.legacy = []byte(legacy)
+		ring.legacyUntil = until
+	}
+
+	var order []string
+	for _, entry := range strings.Split(keyList, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("AUTH_SIGNING_KEYS entry %q must be <key id>:<secret>", entry)
+		}
+		if strings.Contains(id, ".") {
+			return nil, fmt.Errorf("AUTH_SIGNING_KEYS key id %q must not contain '.'", id)
+		}
+		if _, dup := ring.keys[id]; dup {
+			return nil, fmt.Errorf("AUTH_SIGNING_KEYS lists key id %q twice", id)
+		}
+		if len(secret) < minSecretLength {
+			log.Printf("⚠️ Signing key %q is shorter than %d bytes", id, minSecretLength)
+		}
+		ring.keys[id] = []byte(secret)
+		order = append(order, id)
+	}
+
+	if len(order) == 0 {
+		if currentID != "" {
+			return nil, fmt.Errorf("AUTH_SIGNING_KEY_ID is %q but AUTH_SIGNING_KEYS is empty", currentID)
+		}
+		// A random key would log every voter out on each restart
+		if production {
+			return nil, fmt.Errorf("AUTH_SIGNING_KEYS is required when APP_ENV=production")
+		}
+		// Nothing configured - sign with a throwaway key so no secret ships in the repo
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		ring.keys["ephemeral"] = secret
+		ring.currentID = "ephemeral"
+		log.Println("⚠️ AUTH_SIGNING_KEYS not set - using a random signing key, user tokens will not survive a restart")
+		return ring, nil
+	}
+
+	if currentID == "" {
+		currentID = order[0]
+	}
+	if _, ok := ring.keys[currentID]; !ok {
+		return nil, fmt.Errorf("AUTH_SIGNING_KEY_ID %q is not in AUTH_SIGNING_KEYS", currentID)
+	}
+	ring.currentID = currentID
+
+	legacyStatus := "rejected"
+	if ring.legacy != nil {
+		legacyStatus = "accepted until " + ring.legacyUntil.UTC().Format(time.RFC3339)
+	}
+	log.Printf("🔑 Token signing key %q (%d verification keys, legacy tokens %s)", currentID, len(order), legacyStatus)
+	return ring, nil
+}
+
+// acceptsLegacy - Whether two-part legacy tokens are still honoured
+func (ring *signingKeys) acceptsLegacy() bool {
+	return ring.legacy != nil && time.Now().Before(ring.legacyUntil)
+}
+
+// currentKeys - The keyring, loaded on first use if main didn't call InitSigningKeys
+func currentKeys() *signingKeys {
+	if err := InitSigningKeys(); err != nil {
+		log.Fatalf("❌ Invalid token signing keys: %v", err)
+	}
+	return keyring
+}
@@ -0,0 +1,33 @@
+package auth
+
+import "sync"
+
+// revokedTokens - User IDs whose tokens are refused with 403 TOKEN_REVOKED
+var revokedTokens = struct {
+	mu  sync.RWMutex
+	ids map[string]struct{}
+}{ids: make(map[string]struct{})}
+
+// LoadRevokedTokens - Seed the set from storage at startup
+func LoadRevokedTokens(userIDs []string) {
+	revokedTokens.mu.Lock()
+	defer revokedTokens.mu.Unlock()
+	for _, id := range userIDs {
+		revokedTokens.ids[id] = struct{}{}
+	}
+}
+
+// RevokeToken - Reject this user ID from now on (persist it separately)
+func RevokeToken(userID string) {
+	revokedTokens.mu.Lock()
+	revokedTokens.ids[userID] = struct{}{}
+	revokedTokens.mu.Unlock()
+}
+
+// IsRevoked - Whether the user ID has been revoked
+func IsRevoked(userID string) bool {
+	revokedTokens.mu.RLock()
+	_, revoked := revokedTokens.ids[userID]
+	revokedTokens.mu.RUnlock()
+	return revoked
+}
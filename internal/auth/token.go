@@ -5,7 +5,12 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"log"
+	"math"
+	"movie-api/internal/ratelimit"
+	"movie-api/pkg/utils"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,127 +20,243 @@ import (
 
 type TokenPayload struct {
 	UserID    string `json:"user_id"`
-	CreatedAt int64  `json:"created_at"`
+	CreatedAt int64  `json:"created_at"`          // When the user ID was first issued; kept across renewals
+	IssuedAt  int64  `json:"issued_at,omitempty"` // When this cookie was signed (absent on older tokens)
 	ExpiresAt int64  `json:"expires_at"`
 }
 
-var secretKey = []byte("your-secret-key-change-in-production")
-
 // GenerateAndSetToken creates new token and sets HTTP-only cookie
 func GenerateAndSetToken(w http.ResponseWriter) (string, error) {
-	userID := uuid.New().String()
+	return issueToken(w, newTokenPayload())
+}
+
+func newTokenPayload() TokenPayload {
 	now := time.Now()
-	
-	payload := TokenPayload{
-		UserID:    userID,
+	return TokenPayload{
+		UserID:    uuid.New().String(),
 		CreatedAt: now.Unix(),
-		ExpiresAt: now.Add(365 * 24 * time.Hour).Unix(),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(currentCookieConfig().Lifetime).Unix(),
+	}
+}
+
+// renewed - Same user and creation time, fresh lifetime from now
+func (p TokenPayload) renewed() TokenPayload {
+	now := time.Now()
+	p.IssuedAt = now.Unix()
+	p.ExpiresAt = now.Add(currentCookieConfig().Lifetime).Unix()
+	return p
+}
+
+// pastHalfLife - Whether the token has used up half of its lifetime
+func (p TokenPayload) pastHalfLife() bool {
+	issuedAt := p.IssuedAt
+	if issuedAt == 0 {
+		issuedAt = p.CreatedAt
 	}
-	
-	payloadJSON, _ := json.Marshal(payload)
-	mac := hmac.New(sha256.New, secretKey)
-	mac.Write(payloadJSON)
-	signature := mac.Sum(nil)
-	
-	tokenData := base64.URLEncoding.EncodeToString(payloadJSON) + "." + base64.URLEncoding.EncodeToString(signature)
-	
-	http.SetCookie(w, &http.Cookie{
-		Name:     "user_token",
-		Value:    tokenData,
-		Path:     "/",
-		MaxAge:   365 * 24 * 60 * 60,
-		HttpOnly: true,
-		Secure:   false,
-		SameSite: http.SameSiteLaxMode,
-	})
-	
+	return time.Now().Unix() >= issuedAt+(p.ExpiresAt-issuedAt)/2
+}
+
+// issueToken - Sign payload with the current key and set it as the cookie
+func issueToken(w http.ResponseWriter, payload TokenPayload) (string, error) {
+	tokenData, err := encodeToken(payload)
+	if err != nil {
+		return "", err
+	}
+
+	http.SetCookie(w, currentCookieConfig().newCookie(tokenData))
+
 	return tokenData, nil
 }
 
+// encodeToken - "<key id>.<payload>.<signature>", the signature covering key id and payload
+func encodeToken(payload TokenPayload) (string, error) {
+	ring := currentKeys()
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	signed := ring.currentID + "." + base64.URLEncoding.EncodeToString(payloadJSON)
+	signature := tokenSignature(ring.keys[ring.currentID], []byte(signed))
+	return signed + "." + base64.URLEncoding.EncodeToString(signature), nil
+}
+
+func tokenSignature(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// tokenStatus - What decodeToken made of a cookie
+type tokenStatus int
+
+const (
+	tokenInvalid tokenStatus = iota // missing, malformed, badly signed or expired
+	tokenCurrent                    // signed with the current key
+	tokenRotated                    // signed with a retired key that still verifies
+	tokenLegacy                     // old two-part token, accepted until AUTH_LEGACY_UNTIL
+	tokenRevoked                    // genuine, but revoked by an admin
+)
+
+func (s tokenStatus) valid() bool {
+	return s == tokenCurrent || s == tokenRotated || s == tokenLegacy
+}
+
 // ValidateToken validates the token from cookie
 func ValidateToken(r *http.Request) (*TokenPayload, bool) {
-	cookie, err := r.Cookie("user_token")
+	payload, status := readToken(r)
+	return payload, status.valid()
+}
+
+// readToken - Like ValidateToken, also reporting how the token was signed
+func readToken(r *http.Request) (*TokenPayload, tokenStatus) {
+	cookie, err := r.Cookie(currentCookieConfig().Name)
 	if err != nil {
-		return nil, false
+		return nil, tokenInvalid
 	}
-	
-	parts := strings.Split(cookie.Value, ".")
-	if len(parts) != 2 {
-		return nil, false
+	return decodeToken(cookie.Value)
+}
+
+func decodeToken(value string) (*TokenPayload, tokenStatus) {
+	ring := currentKeys()
+	parts := strings.Split(value, ".")
+
+	var key, signed []byte
+	var payloadPart, signaturePart string
+	var status tokenStatus
+
+	switch len(parts) {
+	case 3:
+		keyID := parts[0]
+		key = ring.keys[keyID]
+		if key == nil {
+			return nil, tokenInvalid
+		}
+		payloadPart, signaturePart = parts[1], parts[2]
+		signed = []byte(keyID + "." + payloadPart)
+		status = tokenCurrent
+		if keyID != ring.currentID {
+			status = tokenRotated
+		}
+	case 2:
+		// Issued before key IDs existed - signature is over the raw payload JSON
+		if !ring.acceptsLegacy() {
+			return nil, tokenInvalid
+		}
+		key = ring.legacy
+		payloadPart, signaturePart = parts[0], parts[1]
+		status = tokenLegacy
+	default:
+		return nil, tokenInvalid
 	}
-	
-	payloadJSON, err := base64.URLEncoding.DecodeString(parts[0])
+
+	payloadJSON, err := base64.URLEncoding.DecodeString(payloadPart)
 	if err != nil {
-		return nil, false
+		return nil, tokenInvalid
+	}
+	if signed == nil {
+		signed = payloadJSON
 	}
-	
+
 	// Verify signature
-	mac := hmac.New(sha256.New, secretKey)
-	mac.Write(payloadJSON)
-	expectedSignature := mac.Sum(nil)
-	
-	actualSignature, err := base64.URLEncoding.DecodeString(parts[1])
+	actualSignature, err := base64.URLEncoding.DecodeString(signaturePart)
 	if err != nil {
-		return nil, false
+		return nil, tokenInvalid
 	}
-	
-	if !hmac.Equal(expectedSignature, actualSignature) {
-		return nil, false
+	if !hmac.Equal(tokenSignature(key, signed), actualSignature) {
+		return nil, tokenInvalid
 	}
-	
+
 	// Parse payload
 	var payload TokenPayload
 	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
-		return nil, false
+		return nil, tokenInvalid
 	}
-	
+
 	// Check expiration
 	if time.Now().Unix() > payload.ExpiresAt {
-		return nil, false
+		return nil, tokenInvalid
 	}
-	
-	return &payload, true
+
+	// Reported separately so the middleware refuses the request instead of minting a new identity
+	if IsRevoked(payload.UserID) {
+		return nil, tokenRevoked
+	}
+
+	return &payload, status
 }
 
-// GetOrCreateToken middleware - ensures user has a token
+// GetOrCreateToken middleware - ensures user has a token (mounted on /api/user only, so admin
+// scripts and public pages don't spend the per-IP issuance budget)
 func GetOrCreateToken() gin.HandlerFunc {
+	// New tokens per client IP per hour - stops scripts minting a token per vote
+	issueLimiter := ratelimit.New(utils.GetEnvInt("TOKEN_ISSUE_RATE_PER_IP", 60), time.Hour, utils.GetEnvInt("TOKEN_ISSUE_BURST_PER_IP", 20))
+
 	return func(c *gin.Context) {
-		// Skip token generation for public endpoints
-		publicPaths := []string{"/api/homepage", "/api/movies", "/api/ratings", "/api/health"}
-		path := c.Request.URL.Path
-		
-		isPublic := false
-		for _, publicPath := range publicPaths {
-			if strings.HasPrefix(path, publicPath) {
-				isPublic = true
-				break
+		payload, status := readToken(c.Request)
+
+		if status == tokenRevoked {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"message": "This session has been revoked",
+				"code":    "TOKEN_REVOKED",
+			})
+			c.Abort()
+			return
+		}
+
+		// Legacy tokens can be forged with the published secret, so converting one costs
+		// the same as minting a token
+		if !status.valid() || status == tokenLegacy {
+			if allowed, wait := issueLimiter.Allow(c.ClientIP()); !allowed {
+				retryAfter := int(math.Ceil(wait.Seconds()))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				c.Header("Retry-After", strconv.Itoa(retryAfter))
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"success": false,
+					"message": "Too many new sessions from this address",
+					"code":    "RATE_LIMITED",
+				})
+				c.Abort()
+				return
 			}
 		}
-		
-		if !isPublic {
-			// Only generate token for user routes
-			payload, valid := ValidateToken(c.Request)
-			if !valid {
-				// Generate new token
-				token, err := GenerateAndSetToken(c.Writer)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{
-						"success": false,
-						"message": "Failed to generate user token",
-					})
-					c.Abort()
-					return
+
+		if !status.valid() {
+
+			// Generate new token
+			newPayload := newTokenPayload()
+			if _, err := issueToken(c.Writer, newPayload); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"message": "Failed to generate user token",
+				})
+				c.Abort()
+				return
+			}
+			// Store in context for handlers to use
+			c.Set("user_payload", &newPayload)
+		} else {
+			// Signed with a retired key or past half its lifetime - same identity, current key,
+			// fresh expiry, so rotation keeps votes linked and active voters never expire
+			if status != tokenCurrent || payload.pastHalfLife() {
+				renewed := payload.renewed()
+				// A legacy token's creation time is unverifiable - treat it as new for abuse checks
+				if status == tokenLegacy {
+					renewed.CreatedAt = renewed.IssuedAt
+				}
+				if _, err := issueToken(c.Writer, renewed); err != nil {
+					log.Printf("⚠️ Failed to re-issue token for %s: %v", payload.UserID, err)
+				} else {
+					payload = &renewed
 				}
-				// Store in context for handlers to use
-				parts := strings.Split(token, ".")
-				payloadJSON, _ := base64.URLEncoding.DecodeString(parts[0])
-				var newPayload TokenPayload
-				json.Unmarshal(payloadJSON, &newPayload)
-				c.Set("user_payload", &newPayload)
-			} else {
-				c.Set("user_payload", payload)
 			}
+			c.Set("user_payload", payload)
 		}
 		c.Next()
 	}
-}
\ No newline at end of file
+}
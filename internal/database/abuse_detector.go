@@ -0,0 +1,252 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	abuseScanInterval = time.Minute
+	// A burst is this many brand-new tokens voting from one IP/user-agent within abuseBurstWindow
+	abuseBurstWindow    = 10 * time.Minute
+	abuseBurstThreshold = 20
+	// Tokens younger than this at vote time count as "new"
+	newTokenAge = time.Hour
+	// How long a source stays flagged without review
+	flagDuration       = 24 * time.Hour
+	maxUserAgentLength = 256
+)
+
+type sourceKey struct {
+	clientIP  string
+	userAgent string
+}
+
+// flaggedSources - In-memory copy of vote_flags checked by the flusher
+type flaggedSources struct {
+	mu      sync.RWMutex
+	expires map[sourceKey]time.Time
+}
+
+func newFlaggedSources() *flaggedSources {
+	return &flaggedSources{expires: make(map[sourceKey]time.Time)}
+}
+
+func (fs *flaggedSources) load(db *sql.DB) error {
+	rows, err := db.Query(`SELECT client_ip, user_agent, expires_at FROM vote_flags WHERE expires_at > ?`, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for rows.Next() {
+		var key sourceKey
+		var expiresAt int64
+		if err := rows.Scan(&key.clientIP, &key.userAgent, &expiresAt); err != nil {
+			continue
+		}
+		fs.expires[key] = time.Unix(expiresAt, 0)
+	}
+	return rows.Err()
+}
+
+func (fs *flaggedSources) set(key sourceKey, expiresAt time.Time) {
+	fs.mu.Lock()
+	fs.expires[key] = expiresAt
+	fs.mu.Unlock()
+}
+
+// remove - Drop flags for an IP; an empty userAgent matches every user-agent
+func (fs *flaggedSources) remove(clientIP, userAgent string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for key := range fs.expires {
+		if key.clientIP == clientIP && (userAgent == "" || key.userAgent == userAgent) {
+			delete(fs.expires, key)
+		}
+	}
+}
+
+func (fs *flaggedSources) isFlagged(key sourceKey) bool {
+	fs.mu.RLock()
+	expiresAt, ok := fs.expires[key]
+	fs.mu.RUnlock()
+	return ok && time.Now().Before(expiresAt)
+}
+
+// shouldQuarantine - New-token votes from a flagged source stay out of the aggregates
+func (fs *flaggedSources) shouldQuarantine(vote VoteDelta) bool {
+	if vote.ClientIP == "" || vote.CreatedAt-vote.TokenCreatedAt >= int64(newTokenAge.Seconds()) {
+		return false
+	}
+	return fs.isFlagged(sourceKey{vote.ClientIP, vote.UserAgent})
+}
+
+// FlagSource - Flag an IP/user-agent and quarantine its recent new-token votes (approved votes stay counted)
+func (rm *ResponseManager) FlagSource(clientIP, userAgent string, newTokens int) error {
+	db, err := rm.sqlDB()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	expiresAt := now.Add(flagDuration)
+
+	// Serialize with the flusher so no batch sees a half-applied quarantine
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO vote_flags (client_ip, user_agent, flagged_at, expires_at, new_tokens)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(client_ip, user_agent) DO UPDATE SET
+			flagged_at = excluded.flagged_at,
+			expires_at = excluded.expires_at,
+			new_tokens = excluded.new_tokens
+	`, clientIP, userAgent, now.Unix(), expiresAt.Unix(), newTokens)
+	if err != nil {
+		return err
+	}
+
+	since := now.Add(-abuseBurstWindow).Unix()
+	rows, err := tx.Query(`
+		SELECT movie_slug, option_chosen, voted_at FROM user_responses
+		WHERE client_ip = ? AND user_agent = ? AND quarantined = 0 AND reviewed_at = 0
+		  AND voted_at >= ? AND voted_at - token_created_at < ?
+	`, clientIP, userAgent, since, int64(newTokenAge.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	deltas := newAggregateDeltas()
+	quarantined := 0
+	for rows.Next() {
+		var slug string
+		var option int
+		var votedAt int64
+		if err := rows.Scan(&slug, &option, &votedAt); err != nil {
+			continue
+		}
+		var change [5]int
+		if option >= 0 && option <= 3 {
+			change[option]--
+		}
+		change[4]--
+		deltas.add(slug, votedAt, change)
+		quarantined++
+	}
+	rows.Close()
+
+	_, err = tx.Exec(`
+		UPDATE user_responses SET quarantined = 1
+		WHERE client_ip = ? AND user_agent = ? AND quarantined = 0 AND reviewed_at = 0
+		  AND voted_at >= ? AND voted_at - token_created_at < ?
+	`, clientIP, userAgent, since, int64(newTokenAge.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	if err = deltas.write(tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	rm.flagged.set(sourceKey{clientIP, userAgent}, expiresAt)
+	rm.invalidateCounts(deltas.movies)
+	rm.notifyFlushListeners(deltas.movies)
+
+	log.Printf("🚩 Flagged %s (%d new tokens) - quarantined %d votes", clientIP, newTokens, quarantined)
+	return nil
+}
+
+// AbuseDetector - Looks for bursts of votes from many new tokens sharing an IP/user-agent
+type AbuseDetector struct {
+	rm       *ResponseManager
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+var AbuseDetectorInstance *AbuseDetector
+
+func InitAbuseDetector(rm *ResponseManager) {
+	AbuseDetectorInstance = &AbuseDetector{
+		rm:   rm,
+		stop: make(chan struct{}),
+	}
+	go AbuseDetectorInstance.run()
+}
+
+func (ad *AbuseDetector) run() {
+	ticker := time.NewTicker(abuseScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ad.scan()
+		case <-ad.stop:
+			return
+		}
+	}
+}
+
+// scan - Flag every source over the burst threshold that isn't flagged yet,
+// ignoring votes an admin has already approved
+func (ad *AbuseDetector) scan() {
+	db, err := ad.rm.sqlDB()
+	if err != nil {
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT client_ip, user_agent, COUNT(DISTINCT user_token) AS new_tokens
+		FROM user_responses
+		WHERE voted_at >= ? AND quarantined = 0 AND reviewed_at = 0 AND client_ip != ''
+		  AND voted_at - token_created_at < ?
+		GROUP BY client_ip, user_agent
+		HAVING new_tokens >= ?
+	`, time.Now().Add(-abuseBurstWindow).Unix(), int64(newTokenAge.Seconds()), abuseBurstThreshold)
+	if err != nil {
+		log.Printf("⚠️ Abuse scan failed: %v", err)
+		return
+	}
+
+	type suspect struct {
+		key       sourceKey
+		newTokens int
+	}
+	var suspects []suspect
+	for rows.Next() {
+		var s suspect
+		if err := rows.Scan(&s.key.clientIP, &s.key.userAgent, &s.newTokens); err != nil {
+			continue
+		}
+		if ad.rm.flagged.isFlagged(s.key) {
+			continue
+		}
+		suspects = append(suspects, s)
+	}
+	rows.Close()
+
+	for _, s := range suspects {
+		if err := ad.rm.FlagSource(s.key.clientIP, s.key.userAgent, s.newTokens); err != nil {
+			log.Printf("❌ Failed to flag %s: %v", s.key.clientIP, err)
+		}
+	}
+}
+
+// Shutdown - Stop scanning
+func (ad *AbuseDetector) Shutdown() {
+	ad.stopOnce.Do(func() { close(ad.stop) })
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// aggregateDeltas - Pending changes to movie_responses and movie_vote_buckets
+type aggregateDeltas struct {
+	movies  map[string][5]int
+	buckets map[bucketKey][5]int
+}
+
+func newAggregateDeltas() *aggregateDeltas {
+	return &aggregateDeltas{
+		movies:  make(map[string][5]int),
+		buckets: make(map[bucketKey][5]int),
+	}
+}
+
+// add - Record a change (option_0..3, total) for a movie at a unix time
+func (d *aggregateDeltas) add(movieSlug string, at int64, change [5]int) {
+	if change == ([5]int{}) {
+		return
+	}
+	d.movies[movieSlug] = addDeltas(d.movies[movieSlug], change)
+	for _, granularity := range bucketGranularities {
+		key := newBucketKey(movieSlug, granularity, at)
+		d.buckets[key] = addDeltas(d.buckets[key], change)
+	}
+}
+
+// write - Apply the deltas inside tx; deltas may be negative
+func (d *aggregateDeltas) write(tx *sql.Tx) error {
+	movieResponseStmt, err := tx.Prepare(`
+		INSERT INTO movie_responses (movie_slug, option_0, option_1, option_2, option_3, total_votes)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(movie_slug) DO UPDATE SET
+			option_0 = option_0 + excluded.option_0,
+			option_1 = option_1 + excluded.option_1,
+			option_2 = option_2 + excluded.option_2,
+			option_3 = option_3 + excluded.option_3,
+			total_votes = total_votes + excluded.total_votes
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare movie response statement: %w", err)
+	}
+	defer movieResponseStmt.Close()
+
+	bucketStmt, err := tx.Prepare(`
+		INSERT INTO movie_vote_buckets (movie_slug, granularity, bucket_start, option_0, option_1, option_2, option_3, total_votes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(movie_slug, granularity, bucket_start) DO UPDATE SET
+			option_0 = option_0 + excluded.option_0,
+			option_1 = option_1 + excluded.option_1,
+			option_2 = option_2 + excluded.option_2,
+			option_3 = option_3 + excluded.option_3,
+			total_votes = total_votes + excluded.total_votes
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare vote bucket statement: %w", err)
+	}
+	defer bucketStmt.Close()
+
+	for movieSlug, delta := range d.movies {
+		_, err := movieResponseStmt.Exec(movieSlug, delta[0], delta[1], delta[2], delta[3], delta[4])
+		if err != nil {
+			return fmt.Errorf("update movie response for %s: %w", movieSlug, err)
+		}
+	}
+
+	for key, delta := range d.buckets {
+		_, err := bucketStmt.Exec(key.movieSlug, key.granularity, key.start, delta[0], delta[1], delta[2], delta[3], delta[4])
+		if err != nil {
+			return fmt.Errorf("update %s vote bucket for %s: %w", key.granularity, key.movieSlug, err)
+		}
+	}
+	return nil
+}
+
+func addDeltas(a, b [5]int) [5]int {
+	for i := range a {
+		a[i] += b[i]
+	}
+	return a
+}
@@ -13,6 +13,25 @@ import (
 var DB *sql.DB
 
 func InitDB() error {
+	if err := OpenDB(); err != nil {
+		return err
+	}
+
+	if err := InitMovieCatalog(DB); err != nil {
+		return err
+	}
+
+	// INITIALIZE RESPONSE MANAGER
+	InitResponseManager(NewSQLiteVoteStore(DB), LoadResponseManagerConfig())
+	InitTrendingRanker(DB)
+	InitAbuseDetector(ResponseManagerInstance)
+	
+	log.Println("✅ Database connected successfully")
+	return nil
+}
+
+// OpenDB - Connect and migrate without starting the vote pipeline (used by CLI commands)
+func OpenDB() error {
 	// Use environment variable or fallback to local path
 	dbPath := os.Getenv("DB_PATH")
 	if dbPath == "" {
@@ -50,9 +69,22 @@ func InitDB() error {
 		log.Printf("⚠️ Could not set SQLite optimizations: %v", err)
 	}
 
-	// INITIALIZE RESPONSE MANAGER
-	InitResponseManager(DB)
-	
-	log.Println("✅ Database connected successfully")
-	return nil
-}
\ No newline at end of file
+	return ensureSchema(DB)
+}
+
+// CloseDB - Close the database connection pool
+func CloseDB() error {
+	if DB == nil {
+		return nil
+	}
+	if MovieCatalogInstance != nil {
+		MovieCatalogInstance.Shutdown()
+	}
+	if TrendingRankerInstance != nil {
+		TrendingRankerInstance.Shutdown()
+	}
+	if AbuseDetectorInstance != nil {
+		AbuseDetectorInstance.Shutdown()
+	}
+	return DB.Close()
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// DeadLetter - A vote that could not be applied even on its own
+type DeadLetter struct {
+	ID           int64     `json:"id"`
+	JournalID    int64     `json:"journal_id"`
+	UserToken    string    `json:"user_token"`
+	MovieSlug    string    `json:"movie_slug"`
+	OptionChosen int       `json:"option_chosen"`
+	Retract      bool      `json:"retract"`
+	CreatedAt    time.Time `json:"created_at"`
+	Error        string    `json:"error"`
+	FailedAt     time.Time `json:"failed_at"`
+}
+
+// DeadLetterVote - A dead letter's vote as it was queued, for replay
+type DeadLetterVote struct {
+	ID   int64
+	Vote VoteDelta
+}
+
+// deadLetter - Set a poisoned vote aside; on error it stays journaled and the caller holds it for retry
+func (rm *ResponseManager) deadLetter(vote VoteDelta, cause error) error {
+	if err := rm.store.DeadLetter(vote, cause); err != nil {
+		log.Printf("❌ Failed to dead-letter vote for %s (stays journaled): %v", vote.MovieSlug, err)
+		return err
+	}
+	atomic.AddInt64(&rm.deadLettered, 1)
+	log.Printf("☠️ Dead-lettered vote for %s: %v", vote.MovieSlug, cause)
+	return nil
+}
+
+// ListDeadLetters - Newest dead letters first
+func (rm *ResponseManager) ListDeadLetters(limit, offset int) ([]DeadLetter, int, error) {
+	return rm.store.ListDeadLetters(limit, offset)
+}
+
+// ReplayDeadLetters - Put dead letters back through the vote queue (all when ids is empty).
+// Letters the user has voted past since are dropped instead, reported as superseded.
+func (rm *ResponseManager) ReplayDeadLetters(ids []int64) (replayed, superseded int, err error) {
+	letters, err := rm.store.LoadDeadLetterVotes(ids)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, letter := range letters {
+		newer, err := rm.hasNewerVote(letter.Vote)
+		if err != nil {
+			return replayed, superseded, err
+		}
+		if newer {
+			superseded++
+		} else {
+			// Journaled again by enqueue, so deleting the dead letter loses nothing
+			if err := rm.enqueue(letter.Vote); err != nil {
+				return replayed, superseded, err
+			}
+			replayed++
+		}
+		if err := rm.store.RemoveDeadLetter(letter.ID); err != nil {
+			return replayed, superseded, err
+		}
+	}
+	return replayed, superseded, nil
+}
+
+// hasNewerVote - Whether the user changed this vote after it was cast (queued or recorded)
+func (rm *ResponseManager) hasNewerVote(vote VoteDelta) (bool, error) {
+	if pending, ok := rm.pendingVote(vote.UserToken, vote.MovieSlug); ok && pending.CreatedAt > vote.CreatedAt {
+		return true, nil
+	}
+	stored, found, err := rm.store.GetUserVote(vote.UserToken, vote.MovieSlug)
+	if err != nil {
+		return false, err
+	}
+	return found && stored.VotedAt > vote.CreatedAt, nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// GlobalVoteTotals - Option counts summed over every movie
+type GlobalVoteTotals struct {
+	Option0         int64
+	Option1         int64
+	Option2         int64
+	Option3         int64
+	MoviesWithVotes int64
+}
+
+// Global totals barely move between flushes, so one query per interval is plenty
+const globalTotalsTTL = 5 * time.Minute
+
+type globalTotalsCache struct {
+	mu        sync.Mutex
+	value     GlobalVoteTotals
+	fetchedAt time.Time
+}
+
+// GetGlobalVoteTotals - Cached site-wide totals used for the Bayesian prior
+func (rm *ResponseManager) GetGlobalVoteTotals() GlobalVoteTotals {
+	globalTotals := &rm.globalTotals
+	globalTotals.mu.Lock()
+	defer globalTotals.mu.Unlock()
+
+	if !globalTotals.fetchedAt.IsZero() && time.Since(globalTotals.fetchedAt) < globalTotalsTTL {
+		return globalTotals.value
+	}
+
+	totals, err := rm.store.GetGlobalTotals()
+	if err != nil {
+		log.Printf("⚠️ Failed to load global vote totals: %v", err)
+		return globalTotals.value
+	}
+
+	globalTotals.value = totals
+	globalTotals.fetchedAt = time.Now()
+	return totals
+}
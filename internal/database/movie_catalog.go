@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// MovieCatalog - In-memory slug set used to validate votes without a DB round-trip
+type MovieCatalog struct {
+	db       *sql.DB
+	mu       sync.RWMutex
+	released map[string]bool // slug -> is_released
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+var MovieCatalogInstance *MovieCatalog
+
+// catalogRefreshInterval - Picks up movies imported outside the admin API
+const catalogRefreshInterval = 5 * time.Minute
+
+func InitMovieCatalog(db *sql.DB) error {
+	MovieCatalogInstance = &MovieCatalog{
+		db:       db,
+		released: make(map[string]bool),
+		stop:     make(chan struct{}),
+	}
+
+	if err := MovieCatalogInstance.Reload(); err != nil {
+		return err
+	}
+
+	go MovieCatalogInstance.periodicRefresh()
+	return nil
+}
+
+// Reload - Replace the slug set with the current contents of movies
+func (mc *MovieCatalog) Reload() error {
+	rows, err := mc.db.Query(`SELECT slug, is_released FROM movies`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	released := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		var isReleased bool
+		if err := rows.Scan(&slug, &isReleased); err != nil {
+			continue
+		}
+		released[slug] = isReleased
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	mc.mu.Lock()
+	mc.released = released
+	mc.mu.Unlock()
+
+	log.Printf("🎬 Movie catalog loaded (%d movies)", len(released))
+	return nil
+}
+
+// Set - Record a movie created or updated through the admin API
+func (mc *MovieCatalog) Set(slug string, isReleased bool) {
+	mc.mu.Lock()
+	mc.released[slug] = isReleased
+	mc.mu.Unlock()
+}
+
+// Lookup - Whether the slug exists and is released
+func (mc *MovieCatalog) Lookup(slug string) (exists bool, isReleased bool) {
+	mc.mu.RLock()
+	isReleased, exists = mc.released[slug]
+	mc.mu.RUnlock()
+	return exists, isReleased
+}
+
+// periodicRefresh - Reload the slug set until Shutdown
+func (mc *MovieCatalog) periodicRefresh() {
+	ticker := time.NewTicker(catalogRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := mc.Reload(); err != nil {
+				log.Printf("⚠️ Failed to refresh movie catalog: %v", err)
+			}
+		case <-mc.stop:
+			return
+		}
+	}
+}
+
+// Shutdown - Stop the background refresh
+func (mc *MovieCatalog) Shutdown() {
+	mc.stopOnce.Do(func() { close(mc.stop) })
+}
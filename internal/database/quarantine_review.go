@@ -0,0 +1,258 @@
+package database
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+var ErrEmptyQuarantineFilter = errors.New("at least one filter (or all) is required")
+
+// QuarantineFilter - Selects quarantined votes for listing or bulk review
+type QuarantineFilter struct {
+	MovieSlug  string   `json:"movie_slug"`
+	ClientIP   string   `json:"client_ip"`
+	UserAgent  string   `json:"user_agent"`
+	UserTokens []string `json:"user_tokens"`
+	All        bool     `json:"all"`
+}
+
+func (f QuarantineFilter) isEmpty() bool {
+	return f.MovieSlug == "" && f.ClientIP == "" && f.UserAgent == "" && len(f.UserTokens) == 0
+}
+
+// where - SQL condition for quarantined votes matching the filter
+func (f QuarantineFilter) where() (string, []interface{}) {
+	conditions := []string{"quarantined = 1"}
+	args := []interface{}{}
+
+	if f.MovieSlug != "" {
+		conditions = append(conditions, "movie_slug = ?")
+		args = append(args, f.MovieSlug)
+	}
+	if f.ClientIP != "" {
+		conditions = append(conditions, "client_ip = ?")
+		args = append(args, f.ClientIP)
+	}
+	if f.UserAgent != "" {
+		conditions = append(conditions, "user_agent = ?")
+		args = append(args, f.UserAgent)
+	}
+	if len(f.UserTokens) > 0 {
+		placeholders := make([]string, len(f.UserTokens))
+		for i, token := range f.UserTokens {
+			placeholders[i] = "?"
+			args = append(args, token)
+		}
+		conditions = append(conditions, "user_token IN ("+strings.Join(placeholders, ",")+")")
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+type QuarantinedVote struct {
+	UserToken    string    `json:"user_token"`
+	MovieSlug    string    `json:"movie_slug"`
+	OptionChosen int       `json:"option_chosen"`
+	VotedAt      time.Time `json:"voted_at"`
+	ClientIP     string    `json:"client_ip"`
+	UserAgent    string    `json:"user_agent"`
+	TokenAgeSecs int64     `json:"token_age_seconds"` // Token age when the vote was cast
+}
+
+type QuarantineGroup struct {
+	Key      string    `json:"key"`
+	Votes    int       `json:"votes"`
+	Tokens   int       `json:"tokens"`
+	Movies   int       `json:"movies"`
+	LastVote time.Time `json:"last_vote"`
+}
+
+// Grouping expressions for QuarantineGroups
+var quarantineGroupBy = map[string]string{
+	"movie": "movie_slug",
+	"ip":    "client_ip || ' | ' || user_agent",
+	"token_age": `CASE
+		WHEN voted_at - token_created_at < 60 THEN 'under 1m'
+		WHEN voted_at - token_created_at < 600 THEN '1m-10m'
+		WHEN voted_at - token_created_at < 3600 THEN '10m-1h'
+		ELSE 'over 1h' END`,
+}
+
+// ListQuarantined - Quarantined votes matching filter, newest first
+func (rm *ResponseManager) ListQuarantined(filter QuarantineFilter, limit, offset int) ([]QuarantinedVote, int, error) {
+	db, err := rm.sqlDB()
+	if err != nil {
+		return nil, 0, err
+	}
+	where, args := filter.where()
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM user_responses WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(`
+		SELECT user_token, movie_slug, option_chosen, voted_at, client_ip, user_agent, token_created_at
+		FROM user_responses WHERE `+where+`
+		ORDER BY voted_at DESC LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	votes := []QuarantinedVote{}
+	for rows.Next() {
+		var vote QuarantinedVote
+		var votedAt, tokenCreatedAt int64
+		if err := rows.Scan(&vote.UserToken, &vote.MovieSlug, &vote.OptionChosen, &votedAt,
+			&vote.ClientIP, &vote.UserAgent, &tokenCreatedAt); err != nil {
+			continue
+		}
+		vote.VotedAt = time.Unix(votedAt, 0).UTC()
+		vote.TokenAgeSecs = votedAt - tokenCreatedAt
+		votes = append(votes, vote)
+	}
+	return votes, total, rows.Err()
+}
+
+// QuarantineGroups - Quarantined vote counts grouped by movie, ip or token_age
+func (rm *ResponseManager) QuarantineGroups(groupBy string) ([]QuarantineGroup, error) {
+	expr, ok := quarantineGroupBy[groupBy]
+	if !ok {
+		return nil, errors.New("group_by must be movie, ip or token_age")
+	}
+	db, err := rm.sqlDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT ` + expr + ` AS group_key, COUNT(*), COUNT(DISTINCT user_token),
+		       COUNT(DISTINCT movie_slug), MAX(voted_at)
+		FROM user_responses WHERE quarantined = 1
+		GROUP BY group_key ORDER BY COUNT(*) DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []QuarantineGroup{}
+	for rows.Next() {
+		var group QuarantineGroup
+		var lastVote int64
+		if err := rows.Scan(&group.Key, &group.Votes, &group.Tokens, &group.Movies, &lastVote); err != nil {
+			continue
+		}
+		group.LastVote = time.Unix(lastVote, 0).UTC()
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// ReviewQuarantined - Approve (count in aggregates) or discard matching quarantined votes
+func (rm *ResponseManager) ReviewQuarantined(filter QuarantineFilter, approve bool) (int, error) {
+	if filter.isEmpty() && !filter.All {
+		return 0, ErrEmptyQuarantineFilter
+	}
+	db, err := rm.sqlDB()
+	if err != nil {
+		return 0, err
+	}
+	where, args := filter.where()
+
+	// Serialize with the flusher so aggregates and user_responses move together
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	deltas := newAggregateDeltas()
+	reviewed := 0
+	if approve {
+		rows, err := tx.Query("SELECT movie_slug, option_chosen, voted_at FROM user_responses WHERE "+where, args...)
+		if err != nil {
+			return 0, err
+		}
+		for rows.Next() {
+			var slug string
+			var option int
+			var votedAt int64
+			if err := rows.Scan(&slug, &option, &votedAt); err != nil {
+				continue
+			}
+			var change [5]int
+			if option >= 0 && option <= 3 {
+				change[option]++
+			}
+			change[4]++
+			deltas.add(slug, votedAt, change)
+		}
+		rows.Close()
+
+		// reviewed_at keeps the abuse scan from quarantining the same votes again
+		result, err := tx.Exec("UPDATE user_responses SET quarantined = 0, reviewed_at = ? WHERE "+where,
+			append([]interface{}{time.Now().Unix()}, args...)...)
+		if err != nil {
+			return 0, err
+		}
+		affected, _ := result.RowsAffected()
+		reviewed = int(affected)
+
+		if err := deltas.write(tx); err != nil {
+			return 0, err
+		}
+	} else {
+		// Quarantined votes were never counted, so discarding leaves aggregates alone
+		result, err := tx.Exec("DELETE FROM user_responses WHERE "+where, args...)
+		if err != nil {
+			return 0, err
+		}
+		affected, _ := result.RowsAffected()
+		reviewed = int(affected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	rm.invalidateCounts(deltas.movies)
+	rm.notifyFlushListeners(deltas.movies)
+
+	action := "Discarded"
+	if approve {
+		action = "Approved"
+	}
+	log.Printf("🧹 %s %d quarantined votes", action, reviewed)
+	return reviewed, nil
+}
+
+// UnflagSource - Lift the flag on an IP (and optionally one user-agent)
+func (rm *ResponseManager) UnflagSource(clientIP, userAgent string) (int, error) {
+	db, err := rm.sqlDB()
+	if err != nil {
+		return 0, err
+	}
+
+	query := "DELETE FROM vote_flags WHERE client_ip = ?"
+	args := []interface{}{clientIP}
+	if userAgent != "" {
+		query += " AND user_agent = ?"
+		args = append(args, userAgent)
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	rm.flagged.remove(clientIP, userAgent)
+
+	affected, _ := result.RowsAffected()
+	return int(affected), nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+)
+
+// RatingCache - Sharded LRU of MovieResponseCounts, kept current by the flusher
+type RatingCache struct {
+	shards []*ratingCacheShard
+}
+
+type ratingCacheShard struct {
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List // front = most recently used
+	capacity int
+	// Bumped on every in-place update so a slow DB read cannot overwrite newer counts
+	generation uint64
+	// Slugs with a store write in flight - their DB reads may already include it, so aren't cached
+	updating map[string]int
+}
+
+type ratingCacheEntry struct {
+	slug   string
+	counts MovieResponseCounts
+}
+
+func NewRatingCache(shardCount, capacity int) *RatingCache {
+	if shardCount < 1 {
+		shardCount = 1
+	}
+	perShard := capacity / shardCount
+	if perShard < 1 {
+		perShard = 1
+	}
+
+	rc := &RatingCache{shards: make([]*ratingCacheShard, shardCount)}
+	for i := range rc.shards {
+		rc.shards[i] = &ratingCacheShard{
+			entries:  make(map[string]*list.Element),
+			updating: make(map[string]int),
+			lru:      list.New(),
+			capacity: perShard,
+		}
+	}
+	return rc
+}
+
+func (rc *RatingCache) shardFor(slug string) *ratingCacheShard {
+	h := fnv.New32a()
+	h.Write([]byte(slug))
+	return rc.shards[h.Sum32()%uint32(len(rc.shards))]
+}
+
+// Get - Cached counts, plus the shard generation to pass to Fill on a miss
+func (rc *RatingCache) Get(slug string) (MovieResponseCounts, uint64, bool) {
+	shard := rc.shardFor(slug)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if elem, ok := shard.entries[slug]; ok {
+		shard.lru.MoveToFront(elem)
+		return elem.Value.(*ratingCacheEntry).counts, shard.generation, true
+	}
+	return MovieResponseCounts{}, shard.generation, false
+}
+
+// Fill - Store counts read from the DB unless the shard changed since Get or the movie is being written
+func (rc *RatingCache) Fill(slug string, counts MovieResponseCounts, generation uint64) {
+	shard := rc.shardFor(slug)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if shard.generation != generation || shard.updating[slug] > 0 {
+		return
+	}
+	if elem, ok := shard.entries[slug]; ok {
+		elem.Value.(*ratingCacheEntry).counts = counts
+		shard.lru.MoveToFront(elem)
+		return
+	}
+
+	shard.entries[slug] = shard.lru.PushFront(&ratingCacheEntry{slug: slug, counts: counts})
+	for shard.lru.Len() > shard.capacity {
+		oldest := shard.lru.Back()
+		shard.lru.Remove(oldest)
+		delete(shard.entries, oldest.Value.(*ratingCacheEntry).slug)
+	}
+}
+
+// BeginUpdate - Call before the store commits changes to these movies; until FinishUpdate,
+// reads of them are served but not cached, so a post-commit read can't get the delta twice
+func (rc *RatingCache) BeginUpdate(slugs []string) {
+	for _, slug := range slugs {
+		shard := rc.shardFor(slug)
+		shard.mu.Lock()
+		shard.generation++
+		shard.updating[slug]++
+		shard.mu.Unlock()
+	}
+}
+
+// FinishUpdate - Apply the committed deltas (nil if the write failed) to cached movies in place
+func (rc *RatingCache) FinishUpdate(slugs []string, deltas map[string][5]int) {
+	for _, slug := range slugs {
+		shard := rc.shardFor(slug)
+		shard.mu.Lock()
+		shard.generation++
+		if shard.updating[slug]--; shard.updating[slug] <= 0 {
+			delete(shard.updating, slug)
+		}
+		if delta, changed := deltas[slug]; changed {
+			if elem, ok := shard.entries[slug]; ok {
+				entry := elem.Value.(*ratingCacheEntry)
+				entry.counts = applyCountDelta(entry.counts, delta)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// Invalidate - Drop a movie so the next read goes to the DB; safe after a commit, as any read
+// that started before it is refused by Fill
+func (rc *RatingCache) Invalidate(slug string) {
+	shard := rc.shardFor(slug)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.generation++
+	if elem, ok := shard.entries[slug]; ok {
+		shard.lru.Remove(elem)
+		delete(shard.entries, slug)
+	}
+}
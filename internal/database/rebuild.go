@@ -0,0 +1,142 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+)
+
+// AggregateDrift - A movie whose stored counts differ from its user_responses
+type AggregateDrift struct {
+	MovieSlug string              `json:"movie_slug"`
+	Stored    MovieResponseCounts `json:"stored"`
+	Computed  MovieResponseCounts `json:"computed"`
+}
+
+// RebuildAggregates - Recompute movie_responses from user_responses (one slug or all).
+// Quarantined votes are excluded. With dryRun nothing is written.
+// Use ResponseManager.RebuildAggregates instead while the server is running.
+func RebuildAggregates(db *sql.DB, movieSlug string, dryRun bool) ([]AggregateDrift, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	computedQuery := `
+		SELECT movie_slug,
+		       SUM(option_chosen = 0), SUM(option_chosen = 1),
+		       SUM(option_chosen = 2), SUM(option_chosen = 3), COUNT(*)
+		FROM user_responses WHERE quarantined = 0`
+	storedQuery := `
+		SELECT movie_slug, option_0, option_1, option_2, option_3, total_votes
+		FROM movie_responses`
+	args := []interface{}{}
+	if movieSlug != "" {
+		computedQuery += " AND movie_slug = ?"
+		storedQuery += " WHERE movie_slug = ?"
+		args = append(args, movieSlug)
+	}
+	computedQuery += " GROUP BY movie_slug"
+
+	computed, err := scanCounts(tx, computedQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := scanCounts(tx, storedQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := []AggregateDrift{}
+	for slug, counts := range computed {
+		if stored[slug] != counts {
+			drifts = append(drifts, AggregateDrift{MovieSlug: slug, Stored: stored[slug], Computed: counts})
+		}
+	}
+	for slug, counts := range stored {
+		if _, ok := computed[slug]; !ok && counts != (MovieResponseCounts{}) {
+			drifts = append(drifts, AggregateDrift{MovieSlug: slug, Stored: counts})
+		}
+	}
+
+	if dryRun || len(drifts) == 0 {
+		return drifts, nil
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO movie_responses (movie_slug, option_0, option_1, option_2, option_3, total_votes)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(movie_slug) DO UPDATE SET
+			option_0 = excluded.option_0,
+			option_1 = excluded.option_1,
+			option_2 = excluded.option_2,
+			option_3 = excluded.option_3,
+			total_votes = excluded.total_votes
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	for _, drift := range drifts {
+		c := drift.Computed
+		if _, err := stmt.Exec(drift.MovieSlug, c.Option0, c.Option1, c.Option2, c.Option3, c.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	log.Printf("🔧 Rebuilt aggregates for %d drifted movies", len(drifts))
+	return drifts, nil
+}
+
+func scanCounts(tx *sql.Tx, query string, args ...interface{}) (map[string]MovieResponseCounts, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]MovieResponseCounts)
+	for rows.Next() {
+		var slug string
+		var counts MovieResponseCounts
+		if err := rows.Scan(&slug, &counts.Option0, &counts.Option1, &counts.Option2, &counts.Option3, &counts.Total); err != nil {
+			return nil, err
+		}
+		result[slug] = counts
+	}
+	return result, rows.Err()
+}
+
+// RebuildAggregates - Rebuild while holding the flush lock, then refresh caches
+func (rm *ResponseManager) RebuildAggregates(movieSlug string, dryRun bool) ([]AggregateDrift, error) {
+	db, err := rm.sqlDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	drifts, err := RebuildAggregates(db, movieSlug, dryRun)
+	if err != nil || dryRun {
+		return drifts, err
+	}
+
+	slugs := make([]string, 0, len(drifts))
+	for _, drift := range drifts {
+		rm.cache.Invalidate(drift.MovieSlug)
+		slugs = append(slugs, drift.MovieSlug)
+	}
+	if len(slugs) > 0 {
+		rm.listenersMu.RLock()
+		for _, listener := range rm.flushListeners {
+			listener(slugs)
+		}
+		rm.listenersMu.RUnlock()
+	}
+	return drifts, nil
+}
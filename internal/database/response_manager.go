@@ -2,218 +2,759 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"log"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
 type MovieResponseCounts struct {
-	Option0 int
-	Option1 int  
-	Option2 int
-	Option3 int
-	Total   int
+	Option0 int `json:"option_0"`
+	Option1 int `json:"option_1"`
+	Option2 int `json:"option_2"`
+	Option3 int `json:"option_3"`
+	Total   int `json:"total_votes"`
+}
+
+// VoteSource - Where a vote came from, used by abuse detection
+type VoteSource struct {
+	ClientIP       string
+	UserAgent      string
+	TokenCreatedAt int64 // Unix seconds the voter's token was issued
 }
 
 type VoteDelta struct {
+	JournalID    int64
 	UserToken    string
 	MovieSlug    string
 	OptionChosen int
+	Retract      bool  // Remove the user's vote instead of setting it
+	CreatedAt    int64 // Unix seconds when the vote was accepted
+	VoteSource
+}
+
+// Reasons a vote can be refused by AddResponse
+var (
+	ErrVoteBufferFull      = errors.New("vote buffer is full")
+	ErrVoteManagerInactive = errors.New("vote manager is shutting down")
+	ErrVoteNotPersisted    = errors.New("vote could not be journaled")
+)
+
+// ErrStoreUnsupported - Moderation, history and rebuild run SQL directly and need the SQLite store
+var ErrStoreUnsupported = errors.New("not supported by this vote store")
+
+// VoteStats - Snapshot of vote intake counters
+type VoteStats struct {
+	Accepted         int64 `json:"accepted"`
+	RejectedFull     int64 `json:"rejected_buffer_full"`
+	RejectedInactive int64 `json:"rejected_inactive"`
+	RejectedPersist  int64 `json:"rejected_persist_failed"`
+	FlushFailures    int64 `json:"flush_failures"`
+	DeadLettered     int64 `json:"dead_lettered"`
+	QueueDepth       int   `json:"queue_depth"`
+	QueueCapacity    int   `json:"queue_capacity"`
 }
 
+// ratingCacheShards - Lock striping for the rating cache (size comes from config)
+const ratingCacheShards = 16
+
+// Failed batches are retried with exponential backoff before votes are isolated
+const (
+	flushMaxAttempts  = 3
+	flushRetryBackoff = 100 * time.Millisecond
+)
+
 type ResponseManager struct {
-	db         *sql.DB
-	newVotes   chan VoteDelta
-	voteCount  int64
-	active     int32
-	mu         sync.Mutex
+	store VoteStore
+	// Moderation, history and rebuild queries run straight against SQLite (nil for other stores - use sqlDB)
+	db               *sql.DB
+	cache            *RatingCache
+	newVotes         chan VoteDelta
+	voteCount        int64
+	rejectedFull     int64
+	rejectedInactive int64
+	rejectedPersist  int64
+	flushFailures    int64
+	deadLettered     int64
+	active           int32
+	mu               sync.Mutex
+	stop             chan struct{}
+	cfg              ResponseManagerConfig
+
+	// Single writer: woken by the ticker, or early by enqueue once the queue passes FlushThreshold
+	wake       chan struct{}
+	writerDone chan struct{}
+
+	// Votes the store couldn't take (busy, or failing every vote) - still journaled,
+	// retried ahead of the queue on the next flush. Guarded by mu.
+	held []VoteDelta
+
+	// Tokens whose votes were removed on revocation; their votes still in flight are dropped. Guarded by mu.
+	retractedTokens map[string]bool
+
+	globalTotals globalTotalsCache
+
+	// IP/user-agent pairs whose new-token votes are held out of the aggregates
+	flagged *flaggedSources
+
+	// Called with the changed slugs after every committed flush
+	listenersMu    sync.RWMutex
+	flushListeners []func(movieSlugs []string)
+
+	// Latest queued-but-unflushed change per (user, movie), for read-your-own-vote
+	pendingMu sync.Mutex
+	pending   map[pendingKey]VoteDelta
+}
+
+type pendingKey struct {
+	userToken string
+	movieSlug string
 }
 
 var ResponseManagerInstance *ResponseManager
 
-func InitResponseManager(db *sql.DB) {
-	ResponseManagerInstance = &ResponseManager{
-		db:       db,
-		newVotes: make(chan VoteDelta, 5000),
-		active:   1,
+func InitResponseManager(store VoteStore, cfg ResponseManagerConfig) {
+	cfg = cfg.normalize()
+	ResponseManagerInstance = newResponseManager(store, cfg)
+
+	if sqliteStore, ok := store.(*SQLiteVoteStore); ok {
+		ResponseManagerInstance.db = sqliteStore.db
+		if err := ResponseManagerInstance.flagged.load(sqliteStore.db); err != nil {
+			log.Printf("⚠️ Failed to load flagged vote sources: %v", err)
+		}
 	}
 
-	// Start multiple flushers
-	for i := 0; i < 3; i++ {
-		go ResponseManagerInstance.periodicFlusher()
+	// Votes accepted before a crash or restart are still in the journal
+	ResponseManagerInstance.replayJournal()
+
+	// SQLite allows one writer at a time, so a single flusher is as fast as several
+	go ResponseManagerInstance.periodicFlusher()
+	log.Printf("✅ Response manager initialized - flush every %s or at %d queued, batches of %d, queue %d, cache %d",
+		cfg.FlushInterval, cfg.FlushThreshold, cfg.MaxBatch, cfg.QueueCapacity, cfg.CacheSize)
+}
+
+// newResponseManager - A manager with nothing started yet (no journal replay, no writer)
+func newResponseManager(store VoteStore, cfg ResponseManagerConfig) *ResponseManager {
+	return &ResponseManager{
+		store:      store,
+		cfg:        cfg,
+		cache:      NewRatingCache(ratingCacheShards, cfg.CacheSize),
+		newVotes:   make(chan VoteDelta, cfg.QueueCapacity),
+		active:     1,
+		stop:       make(chan struct{}),
+		wake:       make(chan struct{}, 1),
+		writerDone: make(chan struct{}),
+		pending:    make(map[pendingKey]VoteDelta),
+		flagged:    newFlaggedSources(),
+
+		retractedTokens: make(map[string]bool),
 	}
-	log.Println("✅ Response manager initialized - No cache mode")
 }
 
-// AddResponse - Just push to channel
-func (rm *ResponseManager) AddResponse(userToken, movieSlug string, optionChosen int) bool {
-	if atomic.LoadInt32(&rm.active) == 0 {
-		return false
+// sqlDB - The SQLite handle for queries outside VoteStore, or ErrStoreUnsupported
+func (rm *ResponseManager) sqlDB() (*sql.DB, error) {
+	if rm.db == nil {
+		return nil, ErrStoreUnsupported
 	}
+	return rm.db, nil
+}
 
-	select {
-	case rm.newVotes <- VoteDelta{
+// AddResponse - Journal the vote, then push to channel
+func (rm *ResponseManager) AddResponse(userToken, movieSlug string, optionChosen int, source VoteSource) error {
+	if len(source.UserAgent) > maxUserAgentLength {
+		source.UserAgent = source.UserAgent[:maxUserAgentLength]
+	}
+	return rm.enqueue(VoteDelta{
 		UserToken:    userToken,
 		MovieSlug:    movieSlug,
 		OptionChosen: optionChosen,
-	}:
-		atomic.AddInt64(&rm.voteCount, 1)
+		VoteSource:   source,
+	})
+}
+
+// AddRetraction - Queue removal of a user's vote behind any pending votes
+func (rm *ResponseManager) AddRetraction(userToken, movieSlug string) error {
+	return rm.enqueue(VoteDelta{
+		UserToken: userToken,
+		MovieSlug: movieSlug,
+		Retract:   true,
+	})
+}
+
+// enqueue - Journal a vote change, then push to channel
+func (rm *ResponseManager) enqueue(vote VoteDelta) error {
+	if atomic.LoadInt32(&rm.active) == 0 {
+		atomic.AddInt64(&rm.rejectedInactive, 1)
+		return ErrVoteManagerInactive
+	}
+
+	// Replayed dead letters keep their original time
+	if vote.CreatedAt == 0 {
+		vote.CreatedAt = time.Now().Unix()
+	}
+
+	// Vote must be durable before we report success
+	journalID, err := rm.store.AppendJournal(vote)
+	if err != nil {
+		log.Printf("❌ Failed to journal vote for %s: %v", vote.MovieSlug, err)
+		atomic.AddInt64(&rm.rejectedPersist, 1)
+		return ErrVoteNotPersisted
+	}
+	vote.JournalID = journalID
+
+	if !rm.queueAndTrack(vote) {
+		// Channel full - apply backpressure, caller reports failure so drop the journal entry
+		if err := rm.store.RemoveJournal(journalID); err != nil {
+			log.Printf("⚠️ Failed to remove journal entry %d: %v", journalID, err)
+		}
+		atomic.AddInt64(&rm.rejectedFull, 1)
+		return ErrVoteBufferFull
+	}
+
+	atomic.AddInt64(&rm.voteCount, 1)
+	if len(rm.newVotes) >= rm.cfg.FlushThreshold {
+		rm.wakeWriter()
+	}
+	return nil
+}
+
+// OnFlush - Register a callback for slugs whose counts changed in a flush
+func (rm *ResponseManager) OnFlush(listener func(movieSlugs []string)) {
+	rm.listenersMu.Lock()
+	rm.flushListeners = append(rm.flushListeners, listener)
+	rm.listenersMu.Unlock()
+}
+
+func (rm *ResponseManager) notifyFlushListeners(moviesToUpdate map[string][5]int) {
+	if len(moviesToUpdate) == 0 {
+		return
+	}
+	slugs := make([]string, 0, len(moviesToUpdate))
+	for slug := range moviesToUpdate {
+		slugs = append(slugs, slug)
+	}
+
+	rm.listenersMu.RLock()
+	defer rm.listenersMu.RUnlock()
+	for _, listener := range rm.flushListeners {
+		listener(slugs)
+	}
+}
+
+// invalidateCounts - Drop movies changed by a committed moderation write from the rating cache.
+// Patching them in place would race with reads of the already-committed counts.
+func (rm *ResponseManager) invalidateCounts(moviesChanged map[string][5]int) {
+	for slug := range moviesChanged {
+		rm.cache.Invalidate(slug)
+	}
+}
+
+// Stats - Current vote intake counters and queue depth
+func (rm *ResponseManager) Stats() VoteStats {
+	return VoteStats{
+		Accepted:         atomic.LoadInt64(&rm.voteCount),
+		RejectedFull:     atomic.LoadInt64(&rm.rejectedFull),
+		RejectedInactive: atomic.LoadInt64(&rm.rejectedInactive),
+		RejectedPersist:  atomic.LoadInt64(&rm.rejectedPersist),
+		FlushFailures:    atomic.LoadInt64(&rm.flushFailures),
+		DeadLettered:     atomic.LoadInt64(&rm.deadLettered),
+		QueueDepth:       len(rm.newVotes),
+		QueueCapacity:    cap(rm.newVotes),
+	}
+}
+
+// queueAndTrack - Push to the channel without blocking and, only if that worked, remember it as
+// the newest queued change for this user and movie. A rejected vote leaves the user's older
+// queued vote visible; holding pendingMu means a fast flush can't clear the entry before it's set.
+func (rm *ResponseManager) queueAndTrack(vote VoteDelta) bool {
+	rm.pendingMu.Lock()
+	defer rm.pendingMu.Unlock()
+
+	select {
+	case rm.newVotes <- vote:
+		rm.pending[pendingKey{vote.UserToken, vote.MovieSlug}] = vote
 		return true
 	default:
-		// Channel full - apply backpressure
 		return false
 	}
 }
 
-// GetMovieCounts - Direct DB query (reads are fast anyway)
+// clearPending - Forget a queued change unless a newer one replaced it
+func (rm *ResponseManager) clearPending(vote VoteDelta) {
+	key := pendingKey{vote.UserToken, vote.MovieSlug}
+	rm.pendingMu.Lock()
+	if current, ok := rm.pending[key]; ok && current.JournalID == vote.JournalID {
+		delete(rm.pending, key)
+	}
+	rm.pendingMu.Unlock()
+}
+
+// pendingVote - Queued change for this user and movie, if any
+func (rm *ResponseManager) pendingVote(userToken, movieSlug string) (VoteDelta, bool) {
+	rm.pendingMu.Lock()
+	vote, ok := rm.pending[pendingKey{userToken, movieSlug}]
+	rm.pendingMu.Unlock()
+	return vote, ok
+}
+
+// PendingVotes - This user's queued-but-unflushed changes, retractions included
+func (rm *ResponseManager) PendingVotes(userToken string) []VoteDelta {
+	rm.pendingMu.Lock()
+	defer rm.pendingMu.Unlock()
+
+	var votes []VoteDelta
+	for key, vote := range rm.pending {
+		if key.userToken == userToken {
+			votes = append(votes, vote)
+		}
+	}
+	return votes
+}
+
+// replayJournal - Flush votes left in the journal by a previous run
+func (rm *ResponseManager) replayJournal() {
+	pending, err := rm.store.LoadJournal()
+	if err != nil {
+		log.Printf("❌ Failed to read vote journal: %v", err)
+		return
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	log.Printf("🔁 Replaying %d journaled votes", len(pending))
+	for start := 0; start < len(pending); start += rm.cfg.MaxBatch {
+		end := start + rm.cfg.MaxBatch
+		if end > len(pending) {
+			end = len(pending)
+		}
+		// Once the store stops taking votes, hold the rest for the writer instead of hammering it
+		if len(rm.held) > 0 {
+			rm.held = append(rm.held, pending[start:end]...)
+			continue
+		}
+		rm.held = append(rm.held, rm.flushBatchToDB(pending[start:end])...)
+	}
+}
+
+// GetMovieCounts - Served from the rating cache, store on miss
 func (rm *ResponseManager) GetMovieCounts(movieSlug string) *MovieResponseCounts {
-	var counts MovieResponseCounts
-	err := rm.db.QueryRow(`
-		SELECT option_0, option_1, option_2, option_3, total_votes 
-		FROM movie_responses WHERE movie_slug = ?
-	`, movieSlug).Scan(&counts.Option0, &counts.Option1, &counts.Option2, &counts.Option3, &counts.Total)
-	
+	// Unknown slugs come straight from public URLs - caching them would evict real movies
+	if !isKnownMovie(movieSlug) {
+		return &MovieResponseCounts{}
+	}
+
+	counts, generation, ok := rm.cache.Get(movieSlug)
+	if ok {
+		return &counts
+	}
+
+	counts, err := rm.store.GetCounts(movieSlug)
 	if err != nil {
 		return &MovieResponseCounts{}
 	}
+
+	// Zero counts are cached too, the flusher fills them in
+	rm.cache.Fill(movieSlug, counts, generation)
 	return &counts
 }
 
-// HasUserVoted - Direct DB query (no cache)
+// GetMovieCountsFor - GetMovieCounts for many movies, with one store query for all cache misses
+func (rm *ResponseManager) GetMovieCountsFor(movieSlugs []string) map[string]MovieResponseCounts {
+	counts := make(map[string]MovieResponseCounts, len(movieSlugs))
+	generations := make(map[string]uint64)
+	var misses []string
+
+	for _, slug := range movieSlugs {
+		if !isKnownMovie(slug) {
+			counts[slug] = MovieResponseCounts{}
+			continue
+		}
+		cached, generation, ok := rm.cache.Get(slug)
+		if ok {
+			counts[slug] = cached
+			continue
+		}
+		generations[slug] = generation
+		misses = append(misses, slug)
+	}
+	if len(misses) == 0 {
+		return counts
+	}
+
+	loaded, err := rm.store.GetCountsFor(misses)
+	if err != nil {
+		log.Printf("⚠️ Failed to load counts for %d movies: %v", len(misses), err)
+		for _, slug := range misses {
+			counts[slug] = MovieResponseCounts{}
+		}
+		return counts
+	}
+
+	for _, slug := range misses {
+		// Movies without votes come back absent and are cached as zero
+		counts[slug] = loaded[slug]
+		rm.cache.Fill(slug, loaded[slug], generations[slug])
+	}
+	return counts
+}
+
+// isKnownMovie - Whether the slug is in the movie catalog (all slugs pass until it is loaded)
+func isKnownMovie(movieSlug string) bool {
+	if MovieCatalogInstance == nil {
+		return true
+	}
+	exists, _ := MovieCatalogInstance.Lookup(movieSlug)
+	return exists
+}
+
+// HasUserVoted - Pending votes first, then the store
 func (rm *ResponseManager) HasUserVoted(userToken, movieSlug string) (bool, int) {
-	var option int
-	err := rm.db.QueryRow(`
-		SELECT option_chosen FROM user_responses 
-		WHERE user_token = ? AND movie_slug = ?
-	`, userToken, movieSlug).Scan(&option)
-	
-	if err == nil {
-		return true, option
-	}
-	
+	if vote, ok := rm.pendingVote(userToken, movieSlug); ok {
+		if vote.Retract {
+			return false, 0
+		}
+		return true, vote.OptionChosen
+	}
+
+	vote, found, err := rm.store.GetUserVote(userToken, movieSlug)
+	if err == nil && found {
+		return true, vote.OptionChosen
+	}
+
 	return false, 0
 }
 
-// periodicFlusher - Just flush votes to DB
+// UserVoteChoices - HasUserVoted for many movies at once; slugs the user hasn't voted on are absent
+func (rm *ResponseManager) UserVoteChoices(userToken string, movieSlugs []string) (map[string]int, error) {
+	stored, err := rm.store.GetUserVotes(userToken, movieSlugs)
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make(map[string]int, len(stored))
+	for slug, vote := range stored {
+		choices[slug] = vote.OptionChosen
+	}
+
+	// Queued changes are newer than anything in the store
+	for _, slug := range movieSlugs {
+		vote, ok := rm.pendingVote(userToken, slug)
+		if !ok {
+			continue
+		}
+		if vote.Retract {
+			delete(choices, slug)
+		} else {
+			choices[slug] = vote.OptionChosen
+		}
+	}
+	return choices, nil
+}
+
+// UserVotes - A page of the user's votes, newest first: queued votes lead (they are the newest),
+// then recorded ones, skipping movies with a queued change. Queued retractions are left out.
+func (rm *ResponseManager) UserVotes(userToken string, limit, offset int) ([]UserVoteRecord, int, error) {
+	var queued []UserVoteRecord
+	var skip []string
+	for _, vote := range rm.PendingVotes(userToken) {
+		skip = append(skip, vote.MovieSlug)
+		if !vote.Retract {
+			queued = append(queued, UserVoteRecord{MovieSlug: vote.MovieSlug, OptionChosen: vote.OptionChosen, VotedAt: vote.CreatedAt})
+		}
+	}
+	sortUserVotes(queued)
+
+	page := []UserVoteRecord{}
+	if offset < len(queued) {
+		end := offset + limit
+		if end > len(queued) {
+			end = len(queued)
+		}
+		page = append(page, queued[offset:end]...)
+	}
+
+	storedOffset := offset - len(queued)
+	if storedOffset < 0 {
+		storedOffset = 0
+	}
+	// Asked for even when queued votes fill the page, for the total
+	stored, total, err := rm.store.ListUserVotes(userToken, skip, limit-len(page), storedOffset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(page, stored...), total + len(queued), nil
+}
+
+// sortUserVotes - Newest first, then by slug, the order every vote list uses
+func sortUserVotes(records []UserVoteRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].VotedAt != records[j].VotedAt {
+			return records[i].VotedAt > records[j].VotedAt
+		}
+		return records[i].MovieSlug < records[j].MovieSlug
+	})
+}
+
+// periodicFlusher - The single writer: flush on every tick, or early when woken
 func (rm *ResponseManager) periodicFlusher() {
-	ticker := time.NewTicker(1 * time.Second)
+	defer close(rm.writerDone)
+
+	ticker := time.NewTicker(rm.cfg.FlushInterval)
 	defer ticker.Stop()
 
-	for atomic.LoadInt32(&rm.active) == 1 {
+	for {
 		select {
 		case <-ticker.C:
-			rm.flushAvailableVotes()
+			rm.drainQueue()
+		case <-rm.wake:
+			rm.drainQueue()
+		case <-rm.stop:
+			return
 		}
 	}
 }
 
-// flushAvailableVotes - Process available votes
-func (rm *ResponseManager) flushAvailableVotes() {
+// wakeWriter - Ask the writer to flush now (no-op if a wake-up is already pending)
+func (rm *ResponseManager) wakeWriter() {
+	select {
+	case rm.wake <- struct{}{}:
+	default:
+	}
+}
+
+// drainQueue - Flush full batches until the queue holds less than one, or the store stops taking votes
+func (rm *ResponseManager) drainQueue() {
+	for {
+		if rm.flushAvailableVotes() < rm.cfg.MaxBatch {
+			return
+		}
+	}
+}
+
+// flushAvailableVotes - Flush held votes, topped up to MaxBatch from the queue,
+// returning how many were settled (applied or dead-lettered)
+func (rm *ResponseManager) flushAvailableVotes() int {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
-	// Collect up to 1000 votes
-	votesBatch := make([]VoteDelta, 0, 1000)
-	
-	for i := 0; i < 1000; i++ {
+	votesBatch := make([]VoteDelta, 0, rm.cfg.MaxBatch)
+
+	// Held votes are older than anything queued, so they go first
+	taken := len(rm.held)
+	if taken > rm.cfg.MaxBatch {
+		taken = rm.cfg.MaxBatch
+	}
+	votesBatch = append(votesBatch, rm.held[:taken]...)
+	rm.held = rm.held[taken:]
+
+collect:
+	for len(votesBatch) < rm.cfg.MaxBatch {
 		select {
 		case vote := <-rm.newVotes:
 			votesBatch = append(votesBatch, vote)
 		default:
-			break
+			break collect
 		}
 	}
 
-	if len(votesBatch) > 0 {
-		rm.flushBatchToDB(votesBatch)
+	if len(votesBatch) == 0 {
+		return 0
+	}
+	collected := len(votesBatch)
+
+	unsettled := rm.flushBatchToDB(rm.dropRetracted(votesBatch))
+	if len(unsettled) > 0 {
+		rm.held = append(unsettled, rm.held...)
+		log.Printf("⏸️ Holding %d votes for the next flush (still journaled)", len(rm.held))
 	}
+	return collected - len(unsettled)
 }
 
-// flushBatchToDB - Batch insert without cache
-func (rm *ResponseManager) flushBatchToDB(votes []VoteDelta) {
-	if len(votes) == 0 {
-		return
+// dropRetracted - Discard votes by tokens whose votes were removed, un-journaling them. Caller holds mu.
+func (rm *ResponseManager) dropRetracted(votes []VoteDelta) []VoteDelta {
+	if len(rm.retractedTokens) == 0 {
+		return votes
 	}
 
-	tx, err := rm.db.Begin()
-	if err != nil {
-		log.Printf("❌ Failed to begin transaction: %v", err)
-		return
+	kept := votes[:0]
+	for _, vote := range votes {
+		if !rm.retractedTokens[vote.UserToken] {
+			kept = append(kept, vote)
+			continue
+		}
+		if err := rm.store.RemoveJournal(vote.JournalID); err != nil {
+			log.Printf("⚠️ Failed to remove journal entry %d: %v", vote.JournalID, err)
+		}
+		rm.clearPending(vote)
+	}
+	return kept
+}
+
+// hasHeld - Whether votes are waiting to be retried
+func (rm *ResponseManager) hasHeld() bool {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return len(rm.held) > 0
+}
+
+// collapseVotes - Keep only the last vote per (user, movie) within a batch
+func collapseVotes(votes []VoteDelta) []VoteDelta {
+	type voteKey struct{ userToken, movieSlug string }
+
+	latest := make(map[voteKey]int, len(votes))
+	collapsed := make([]VoteDelta, 0, len(votes))
+
+	for _, vote := range votes {
+		key := voteKey{vote.UserToken, vote.MovieSlug}
+		if idx, seen := latest[key]; seen {
+			collapsed[idx] = vote
+			continue
+		}
+		latest[key] = len(collapsed)
+		collapsed = append(collapsed, vote)
 	}
+	return collapsed
+}
+
+// flushBatchToDB - Apply a batch atomically, retrying with backoff, then isolate poisoned votes.
+// Returns the votes that are neither applied nor dead-lettered; they stay journaled and pending.
+func (rm *ResponseManager) flushBatchToDB(votes []VoteDelta) []VoteDelta {
+	if len(votes) == 0 {
+		return nil
+	}
+
+	flushBatchSize.Observe(float64(len(votes)))
+	started := time.Now()
+
+	var unsettled []VoteDelta
 	defer func() {
-		if err != nil {
-			tx.Rollback()
+		flushDuration.Observe(time.Since(started).Seconds())
+		held := make(map[int64]bool, len(unsettled))
+		for _, vote := range unsettled {
+			held[vote.JournalID] = true
+		}
+		for _, vote := range votes {
+			if !held[vote.JournalID] {
+				rm.clearPending(vote)
+			}
 		}
 	}()
 
-	userResponseStmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO user_responses (user_token, movie_slug, option_chosen) 
-		VALUES (?, ?, ?)
-	`)
-	if err != nil {
-		log.Printf("❌ Failed to prepare user response statement: %v", err)
-		return
-	}
-	defer userResponseStmt.Close()
-
-	movieResponseStmt, err := tx.Prepare(`
-		INSERT INTO movie_responses (movie_slug, option_0, option_1, option_2, option_3, total_votes)
-		VALUES (?, 0, 0, 0, 0, 0)
-		ON CONFLICT(movie_slug) DO UPDATE SET
-			option_0 = option_0 + ?,
-			option_1 = option_1 + ?,
-			option_2 = option_2 + ?,
-			option_3 = option_3 + ?,
-			total_votes = total_votes + ?
-	`)
-	if err != nil {
-		log.Printf("❌ Failed to prepare movie response statement: %v", err)
-		return
+	var err error
+	for attempt := 0; attempt < flushMaxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(flushRetryBackoff << (attempt - 1))
+		}
+		if err = rm.applyBatch(votes); err == nil {
+			return nil
+		}
+		atomic.AddInt64(&rm.flushFailures, 1)
+		log.Printf("❌ Flush attempt %d/%d for %d votes failed: %v", attempt+1, flushMaxAttempts, len(votes), err)
 	}
-	defer movieResponseStmt.Close()
 
-	moviesToUpdate := make(map[string][5]int)
-	successfulVotes := 0
+	// A busy or locked store isn't the votes' fault
+	if errors.Is(err, ErrStoreBusy) {
+		unsettled = votes
+		return unsettled
+	}
 
-	for _, vote := range votes {
-		_, err := userResponseStmt.Exec(vote.UserToken, vote.MovieSlug, vote.OptionChosen)
-		if err != nil {
-			log.Printf("❌ Failed to update user response for %s: %v", vote.MovieSlug, err)
+	// Replay one at a time, in order, so a single bad vote can't hold back the rest
+	if len(votes) > 1 {
+		log.Printf("⚠️ Splitting failed batch of %d votes", len(votes))
+	}
+	type failedVote struct {
+		vote VoteDelta
+		err  error
+	}
+	var failed []failedVote
+	for i, vote := range votes {
+		err := rm.applyBatch([]VoteDelta{vote})
+		if err == nil {
 			continue
 		}
+		if errors.Is(err, ErrStoreBusy) {
+			unsettled = append(unsettled, votes[i:]...)
+			break
+		}
+		failed = append(failed, failedVote{vote, err})
+	}
 
-		// No cache update - just update aggregation
-		delta := moviesToUpdate[vote.MovieSlug]
-		switch vote.OptionChosen {
-		case 0: delta[0]++
-		case 1: delta[1]++
-		case 2: delta[2]++
-		case 3: delta[3]++
+	// Nothing applied even on its own - the store is failing, not these votes
+	if len(failed)+len(unsettled) == len(votes) {
+		unsettled = votes
+		return unsettled
+	}
+
+	for _, f := range failed {
+		if err := rm.deadLetter(f.vote, f.err); err != nil {
+			unsettled = append(unsettled, f.vote)
 		}
-		delta[4]++
-		moviesToUpdate[vote.MovieSlug] = delta
-		successfulVotes++
 	}
+	return unsettled
+}
 
-	for movieSlug, delta := range moviesToUpdate {
-		_, err := movieResponseStmt.Exec(movieSlug, delta[0], delta[1], delta[2], delta[3], delta[4])
-		if err != nil {
-			log.Printf("❌ Failed to update movie response for %s: %v", movieSlug, err)
+// applyBatch - Record the whole batch atomically; any failure leaves the store untouched
+func (rm *ResponseManager) applyBatch(votes []VoteDelta) error {
+	collapsed := collapseVotes(votes)
+	batch := VoteBatch{
+		Votes:      collapsed,
+		JournalIDs: make([]int64, 0, len(votes)),
+		Quarantine: rm.flagged.shouldQuarantine,
+	}
+	for _, vote := range votes {
+		if vote.JournalID != 0 {
+			batch.JournalIDs = append(batch.JournalIDs, vote.JournalID)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		log.Printf("❌ Failed to commit: %v", err)
-		return
+	// Cached counts must not be refilled from the store between the commit and applying its deltas
+	slugs := batchSlugs(collapsed)
+	rm.cache.BeginUpdate(slugs)
+	result, err := rm.store.RecordBatch(batch)
+	if err != nil {
+		rm.cache.FinishUpdate(slugs, nil)
+		return err
 	}
 
-	log.Printf("📤 Flushed %d/%d votes (%d movies updated)", successfulVotes, len(votes), len(moviesToUpdate))
+	rm.cache.FinishUpdate(slugs, result.Deltas)
+	rm.notifyFlushListeners(result.Deltas)
+
+	log.Printf("📤 Flushed %d votes (%d collapsed, %d changed, %d retracted, %d quarantined, %d superseded, %d movies updated)",
+		len(votes), len(votes)-len(collapsed), result.Changed, result.Retracted, result.Quarantined, result.Superseded, len(result.Deltas))
+	return nil
 }
 
-// Shutdown - Clean shutdown
+// batchSlugs - Distinct movies in a batch
+func batchSlugs(votes []VoteDelta) []string {
+	seen := make(map[string]bool, len(votes))
+	slugs := make([]string, 0, len(votes))
+	for _, vote := range votes {
+		if !seen[vote.MovieSlug] {
+			seen[vote.MovieSlug] = true
+			slugs = append(slugs, vote.MovieSlug)
+		}
+	}
+	return slugs
+}
+
+// Shutdown - Stop accepting votes, stop the writer and drain the whole channel
 func (rm *ResponseManager) Shutdown() {
-	atomic.StoreInt32(&rm.active, 0)
-	time.Sleep(2 * time.Second)
-	rm.flushAvailableVotes()
-}
\ No newline at end of file
+	if !atomic.CompareAndSwapInt32(&rm.active, 1, 0) {
+		return
+	}
+
+	close(rm.stop)
+	<-rm.writerDone
+
+	for len(rm.newVotes) > 0 || rm.hasHeld() {
+		if rm.flushAvailableVotes() == 0 {
+			log.Println("⚠️ Response manager stopped - unflushed votes stay journaled for the next start")
+			return
+		}
+	}
+	log.Println("✅ Response manager stopped - all queued votes flushed")
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"movie-api/pkg/utils"
+	"time"
+)
+
+// ResponseManagerConfig - Tuning knobs for the vote pipeline
+type ResponseManagerConfig struct {
+	FlushInterval  time.Duration // Flush whatever is queued at least this often
+	MaxBatch       int           // Votes per flush transaction
+	QueueCapacity  int           // Buffered votes before AddResponse returns ErrVoteBufferFull
+	FlushThreshold int           // Wake the writer early once this many votes are queued
+	CacheSize      int           // Movies kept in the rating cache
+}
+
+// DefaultResponseManagerConfig - The values the pipeline shipped with
+func DefaultResponseManagerConfig() ResponseManagerConfig {
+	return ResponseManagerConfig{
+		FlushInterval:  1 * time.Second,
+		MaxBatch:       1000,
+		QueueCapacity:  5000,
+		FlushThreshold: 1000,
+		CacheSize:      10000,
+	}
+}
+
+// LoadResponseManagerConfig - Defaults overridden by VOTE_* environment variables
+func LoadResponseManagerConfig() ResponseManagerConfig {
+	cfg := DefaultResponseManagerConfig()
+	cfg.FlushInterval = utils.GetEnvDuration("VOTE_FLUSH_INTERVAL", cfg.FlushInterval)
+	cfg.MaxBatch = utils.GetEnvInt("VOTE_FLUSH_MAX_BATCH", cfg.MaxBatch)
+	cfg.QueueCapacity = utils.GetEnvInt("VOTE_QUEUE_CAPACITY", cfg.QueueCapacity)
+	cfg.FlushThreshold = utils.GetEnvInt("VOTE_FLUSH_THRESHOLD", cfg.FlushThreshold)
+	cfg.CacheSize = utils.GetEnvInt("VOTE_RATING_CACHE_SIZE", cfg.CacheSize)
+	return cfg.normalize()
+}
+
+// normalize - Replace unusable values so a bad env var can't stall the pipeline
+func (cfg ResponseManagerConfig) normalize() ResponseManagerConfig {
+	defaults := DefaultResponseManagerConfig()
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaults.FlushInterval
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = defaults.MaxBatch
+	}
+	if cfg.QueueCapacity <= 0 {
+		cfg.QueueCapacity = defaults.QueueCapacity
+	}
+	if cfg.FlushThreshold <= 0 || cfg.FlushThreshold > cfg.QueueCapacity {
+		cfg.FlushThreshold = cfg.QueueCapacity
+	}
+	if cfg.CacheSize <= 0 {
+		cfg.CacheSize = defaults.CacheSize
+	}
+	return cfg
+}
@@ -0,0 +1,452 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// failingStore - MemoryVoteStore whose RecordBatch fails on demand
+type failingStore struct {
+	*MemoryVoteStore
+	failAll     error           // every batch fails with this
+	poisoned    map[string]bool // batches containing these slugs fail
+	afterCommit func()          // runs after a batch commits, before RecordBatch returns
+}
+
+func (s *failingStore) RecordBatch(batch VoteBatch) (BatchResult, error) {
+	if s.failAll != nil {
+		return BatchResult{}, s.failAll
+	}
+	for _, vote := range batch.Votes {
+		if s.poisoned[vote.MovieSlug] {
+			return BatchResult{}, fmt.Errorf("bad vote for %s", vote.MovieSlug)
+		}
+	}
+	result, err := s.MemoryVoteStore.RecordBatch(batch)
+	if err == nil && s.afterCommit != nil {
+		s.afterCommit()
+	}
+	return result, err
+}
+
+func newTestManager(store VoteStore) *ResponseManager {
+	cfg := DefaultResponseManagerConfig()
+	cfg.MaxBatch = 10
+	cfg.QueueCapacity = 100
+	return newResponseManager(store, cfg.normalize())
+}
+
+// queueVote - Journal and queue a vote the way AddResponse does (CreatedAt defaults to now)
+func queueVote(t *testing.T, rm *ResponseManager, vote VoteDelta) {
+	t.Helper()
+	if err := rm.enqueue(vote); err != nil {
+		t.Fatalf("enqueue %s/%s: %v", vote.UserToken, vote.MovieSlug, err)
+	}
+}
+
+func TestCollapseVotes(t *testing.T) {
+	votes := []VoteDelta{
+		{JournalID: 1, UserToken: "u1", MovieSlug: "a", OptionChosen: 0},
+		{JournalID: 2, UserToken: "u2", MovieSlug: "a", OptionChosen: 1},
+		{JournalID: 3, UserToken: "u1", MovieSlug: "b", OptionChosen: 2},
+		{JournalID: 4, UserToken: "u1", MovieSlug: "a", OptionChosen: 3},
+		{JournalID: 5, UserToken: "u1", MovieSlug: "a", Retract: true},
+	}
+
+	collapsed := collapseVotes(votes)
+
+	want := []int64{5, 2, 3}
+	if len(collapsed) != len(want) {
+		t.Fatalf("got %d votes, want %d", len(collapsed), len(want))
+	}
+	for i, id := range want {
+		if collapsed[i].JournalID != id {
+			t.Errorf("position %d: got journal %d, want %d", i, collapsed[i].JournalID, id)
+		}
+	}
+}
+
+func TestPlanVote(t *testing.T) {
+	tests := []struct {
+		name       string
+		prior      StoredVote
+		hadPrior   bool
+		vote       VoteDelta
+		quarantine bool
+		want       voteOutcome
+	}{
+		{
+			name: "first vote",
+			vote: VoteDelta{OptionChosen: 2, CreatedAt: 100},
+			want: voteOutcome{write: true, change: [5]int{0, 0, 1, 0, 1}},
+		},
+		{
+			name:     "re-vote moves the count",
+			prior:    StoredVote{OptionChosen: 1, VotedAt: 100},
+			hadPrior: true,
+			vote:     VoteDelta{OptionChosen: 3, CreatedAt: 200},
+			want:     voteOutcome{write: true, changed: true, change: [5]int{0, -1, 0, 1, 0}},
+		},
+		{
+			name:     "same answer again",
+			prior:    StoredVote{OptionChosen: 1, VotedAt: 100},
+			hadPrior: true,
+			vote:     VoteDelta{OptionChosen: 1, CreatedAt: 200},
+			want:     voteOutcome{},
+		},
+		{
+			name:     "retract with a prior vote",
+			prior:    StoredVote{OptionChosen: 0, VotedAt: 100},
+			hadPrior: true,
+			vote:     VoteDelta{Retract: true, CreatedAt: 200},
+			want:     voteOutcome{remove: true, change: [5]int{-1, 0, 0, 0, -1}},
+		},
+		{
+			name: "retract without a prior vote",
+			vote: VoteDelta{Retract: true, CreatedAt: 200},
+			want: voteOutcome{},
+		},
+		{
+			name:     "re-vote over a quarantined prior",
+			prior:    StoredVote{OptionChosen: 1, Quarantined: true, VotedAt: 100},
+			hadPrior: true,
+			vote:     VoteDelta{OptionChosen: 2, CreatedAt: 200},
+			want:     voteOutcome{write: true, changed: true, change: [5]int{0, 0, 1, 0, 1}},
+		},
+		{
+			name:     "retract a quarantined prior",
+			prior:    StoredVote{OptionChosen: 1, Quarantined: true, VotedAt: 100},
+			hadPrior: true,
+			vote:     VoteDelta{Retract: true, CreatedAt: 200},
+			want:     voteOutcome{remove: true},
+		},
+		{
+			name:       "quarantined vote stays out of the counts",
+			vote:       VoteDelta{OptionChosen: 2, CreatedAt: 100},
+			quarantine: true,
+			want:       voteOutcome{write: true, quarantine: true},
+		},
+		{
+			name:     "older than the stored vote",
+			prior:    StoredVote{OptionChosen: 1, VotedAt: 200},
+			hadPrior: true,
+			vote:     VoteDelta{OptionChosen: 3, CreatedAt: 100},
+			want:     voteOutcome{superseded: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planVote(tt.prior, tt.hadPrior, tt.vote, tt.quarantine)
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasUserVotedReadsPendingVotes(t *testing.T) {
+	store := NewMemoryVoteStore()
+	rm := newTestManager(store)
+
+	// Recorded vote
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1})
+	rm.flushAvailableVotes()
+	if voted, option := rm.HasUserVoted("u1", "a"); !voted || option != 1 {
+		t.Fatalf("recorded vote: got (%t, %d), want (true, 1)", voted, option)
+	}
+
+	// Queued re-vote wins over the store
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 3})
+	if voted, option := rm.HasUserVoted("u1", "a"); !voted || option != 3 {
+		t.Errorf("pending re-vote: got (%t, %d), want (true, 3)", voted, option)
+	}
+
+	// Queued retraction hides the recorded vote
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", Retract: true})
+	if voted, _ := rm.HasUserVoted("u1", "a"); voted {
+		t.Errorf("pending retraction: still reported as voted")
+	}
+
+	rm.flushAvailableVotes()
+	if voted, _ := rm.HasUserVoted("u1", "a"); voted {
+		t.Errorf("flushed retraction: still reported as voted")
+	}
+	if counts, _ := store.GetCounts("a"); counts.Total != 0 {
+		t.Errorf("flushed retraction: total %d, want 0", counts.Total)
+	}
+}
+
+func TestUserVoteChoicesMergesPendingVotes(t *testing.T) {
+	rm := newTestManager(NewMemoryVoteStore())
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 0})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "b", OptionChosen: 1})
+	queueVote(t, rm, VoteDelta{UserToken: "u2", MovieSlug: "c", OptionChosen: 2})
+	rm.flushAvailableVotes()
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", Retract: true})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "b", OptionChosen: 3})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "d", OptionChosen: 2})
+
+	choices, err := rm.UserVoteChoices("u1", []string{"a", "b", "c", "d", "e"})
+	if err != nil {
+		t.Fatalf("UserVoteChoices: %v", err)
+	}
+
+	want := map[string]int{"b": 3, "d": 2}
+	if len(choices) != len(want) {
+		t.Fatalf("got %v, want %v", choices, want)
+	}
+	for slug, option := range want {
+		if choices[slug] != option {
+			t.Errorf("%s: got %d, want %d", slug, choices[slug], option)
+		}
+	}
+}
+
+func TestFlushDeadLettersOnlyThePoisonedVote(t *testing.T) {
+	store := &failingStore{MemoryVoteStore: NewMemoryVoteStore(), poisoned: map[string]bool{"bad": true}}
+	rm := newTestManager(store)
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "bad", OptionChosen: 2})
+	queueVote(t, rm, VoteDelta{UserToken: "u2", MovieSlug: "a", OptionChosen: 3})
+
+	if settled := rm.flushAvailableVotes(); settled != 3 {
+		t.Fatalf("settled %d votes, want 3", settled)
+	}
+	if rm.hasHeld() {
+		t.Errorf("votes held after a split that isolated the bad vote")
+	}
+
+	if counts, _ := store.GetCounts("a"); counts.Total != 2 {
+		t.Errorf("movie a: total %d, want 2", counts.Total)
+	}
+	letters, total, _ := store.ListDeadLetters(10, 0)
+	if total != 1 || letters[0].MovieSlug != "bad" {
+		t.Fatalf("dead letters: got %+v, want one for bad", letters)
+	}
+	if journal, _ := store.LoadJournal(); len(journal) != 0 {
+		t.Errorf("journal still holds %d votes", len(journal))
+	}
+	if voted, _ := rm.HasUserVoted("u1", "bad"); voted {
+		t.Errorf("dead-lettered vote still reported as pending")
+	}
+}
+
+func TestFlushHoldsVotesWhenEveryVoteFails(t *testing.T) {
+	for name, cause := range map[string]error{
+		"store failing": errors.New("disk I/O error"),
+		"store busy":    fmt.Errorf("%w: database is locked", ErrStoreBusy),
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := &failingStore{MemoryVoteStore: NewMemoryVoteStore(), failAll: cause}
+			rm := newTestManager(store)
+
+			queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1})
+			queueVote(t, rm, VoteDelta{UserToken: "u2", MovieSlug: "b", OptionChosen: 2})
+
+			if settled := rm.flushAvailableVotes(); settled != 0 {
+				t.Fatalf("settled %d votes, want 0", settled)
+			}
+			if _, total, _ := store.ListDeadLetters(10, 0); total != 0 {
+				t.Errorf("dead-lettered %d votes while the store was down", total)
+			}
+			if journal, _ := store.LoadJournal(); len(journal) != 2 {
+				t.Errorf("journal holds %d votes, want 2", len(journal))
+			}
+			if voted, option := rm.HasUserVoted("u1", "a"); !voted || option != 1 {
+				t.Errorf("held vote: got (%t, %d), want (true, 1)", voted, option)
+			}
+
+			// Store recovers - held votes go through with the next flush
+			store.failAll = nil
+			queueVote(t, rm, VoteDelta{UserToken: "u3", MovieSlug: "a", OptionChosen: 1})
+			if settled := rm.flushAvailableVotes(); settled != 3 {
+				t.Fatalf("after recovery settled %d votes, want 3", settled)
+			}
+			if counts, _ := store.GetCounts("a"); counts.Option1 != 2 {
+				t.Errorf("movie a: option_1 %d, want 2", counts.Option1)
+			}
+			if journal, _ := store.LoadJournal(); len(journal) != 0 {
+				t.Errorf("journal still holds %d votes", len(journal))
+			}
+		})
+	}
+}
+
+func TestReplayedDeadLetterDoesNotOverwriteNewerVote(t *testing.T) {
+	store := &failingStore{MemoryVoteStore: NewMemoryVoteStore(), poisoned: map[string]bool{"a": true}}
+	rm := newTestManager(store)
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1, CreatedAt: 100})
+	queueVote(t, rm, VoteDelta{UserToken: "u2", MovieSlug: "b", OptionChosen: 1, CreatedAt: 100})
+	rm.flushAvailableVotes()
+
+	// The user votes again once the problem is fixed
+	store.poisoned = nil
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 3, CreatedAt: 200})
+	rm.flushAvailableVotes()
+
+	replayed, superseded, err := rm.ReplayDeadLetters(nil)
+	if err != nil {
+		t.Fatalf("ReplayDeadLetters: %v", err)
+	}
+	if replayed != 0 || superseded != 1 {
+		t.Errorf("got %d replayed, %d superseded, want 0 and 1", replayed, superseded)
+	}
+	if voted, option := rm.HasUserVoted("u1", "a"); !voted || option != 3 {
+		t.Errorf("got (%t, %d), want the newer vote (true, 3)", voted, option)
+	}
+	if _, total, _ := store.ListDeadLetters(10, 0); total != 0 {
+		t.Errorf("%d dead letters left", total)
+	}
+}
+
+func TestCountsReadDuringFlushAreNotCountedTwice(t *testing.T) {
+	for name, cachedBefore := range map[string]bool{"not cached": false, "cached": true} {
+		t.Run(name, func(t *testing.T) {
+			store := &failingStore{MemoryVoteStore: NewMemoryVoteStore()}
+			rm := newTestManager(store)
+			if cachedBefore {
+				rm.GetMovieCounts("a")
+			}
+
+			// A read that lands after the commit but before the cache catches up
+			var duringFlush *MovieResponseCounts
+			store.afterCommit = func() { duringFlush = rm.GetMovieCounts("a") }
+
+			queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1})
+			rm.flushAvailableVotes()
+
+			if duringFlush == nil || duringFlush.Total > 1 {
+				t.Errorf("read during flush: got %+v, want at most one vote", duringFlush)
+			}
+			if counts := rm.GetMovieCounts("a"); counts.Total != 1 || counts.Option1 != 1 {
+				t.Errorf("after flush: got %+v, want one vote for option 1", counts)
+			}
+		})
+	}
+}
+
+func TestInvalidateRefusesFillFromEarlierRead(t *testing.T) {
+	rc := NewRatingCache(1, 10)
+
+	_, generation, _ := rc.Get("a")
+	rc.Invalidate("a") // a write committed while the read was in flight
+	rc.Fill("a", MovieResponseCounts{Total: 5}, generation)
+
+	if _, _, ok := rc.Get("a"); ok {
+		t.Errorf("counts read before the write were cached")
+	}
+}
+
+func TestUnknownSlugsAreNotCached(t *testing.T) {
+	MovieCatalogInstance = &MovieCatalog{released: map[string]bool{"a": true}}
+	t.Cleanup(func() { MovieCatalogInstance = nil })
+	rm := newTestManager(NewMemoryVoteStore())
+
+	if counts := rm.GetMovieCounts("no-such-movie"); counts.Total != 0 {
+		t.Errorf("unknown movie: got %+v, want zero counts", counts)
+	}
+	rm.GetMovieCountsFor([]string{"a", "also-missing"})
+
+	for slug, wantCached := range map[string]bool{"a": true, "no-such-movie": false, "also-missing": false} {
+		if _, _, cached := rm.cache.Get(slug); cached != wantCached {
+			t.Errorf("%s: cached %t, want %t", slug, cached, wantCached)
+		}
+	}
+}
+
+func TestRejectedVoteKeepsOlderPendingVote(t *testing.T) {
+	cfg := DefaultResponseManagerConfig()
+	cfg.QueueCapacity = 1
+	store := NewMemoryVoteStore()
+	rm := newResponseManager(store, cfg.normalize())
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1})
+	if err := rm.enqueue(VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 2}); !errors.Is(err, ErrVoteBufferFull) {
+		t.Fatalf("second vote: got %v, want ErrVoteBufferFull", err)
+	}
+
+	if voted, option := rm.HasUserVoted("u1", "a"); !voted || option != 1 {
+		t.Errorf("got (%t, %d), want the queued vote (true, 1)", voted, option)
+	}
+	if journal, _ := store.LoadJournal(); len(journal) != 1 {
+		t.Errorf("journal holds %d votes, want 1", len(journal))
+	}
+}
+
+func TestRemoveUserVotesDropsQueuedAndLateVotes(t *testing.T) {
+	store := NewMemoryVoteStore()
+	rm := newTestManager(store)
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1})
+	queueVote(t, rm, VoteDelta{UserToken: "u2", MovieSlug: "a", OptionChosen: 1})
+	rm.flushAvailableVotes()
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "b", OptionChosen: 2})
+	queueVote(t, rm, VoteDelta{UserToken: "u2", MovieSlug: "b", OptionChosen: 2})
+
+	removed, err := rm.RemoveUserVotes("u1")
+	if err != nil {
+		t.Fatalf("RemoveUserVotes: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed %d votes, want 1", removed)
+	}
+
+	// A request that passed the token check before revocation
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "c", OptionChosen: 3})
+	rm.flushAvailableVotes()
+
+	if slugs, _ := store.GetUserVoteSlugs("u1"); len(slugs) != 0 {
+		t.Errorf("revoked token still has votes on %v", slugs)
+	}
+	for slug, want := range map[string]int{"a": 1, "b": 1, "c": 0} {
+		if counts, _ := store.GetCounts(slug); counts.Total != want {
+			t.Errorf("movie %s: total %d, want %d", slug, counts.Total, want)
+		}
+	}
+	if journal, _ := store.LoadJournal(); len(journal) != 0 {
+		t.Errorf("journal still holds %d votes", len(journal))
+	}
+	if voted, _ := rm.HasUserVoted("u1", "b"); voted {
+		t.Errorf("dropped vote still reported as pending")
+	}
+}
+
+func TestUserVotesPagesQueuedThenRecorded(t *testing.T) {
+	rm := newTestManager(NewMemoryVoteStore())
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 1, CreatedAt: 100})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "b", OptionChosen: 1, CreatedAt: 200})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "c", OptionChosen: 1, CreatedAt: 300})
+	queueVote(t, rm, VoteDelta{UserToken: "u2", MovieSlug: "e", OptionChosen: 1, CreatedAt: 300})
+	rm.flushAvailableVotes()
+
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "b", Retract: true, CreatedAt: 350})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "a", OptionChosen: 3, CreatedAt: 400})
+	queueVote(t, rm, VoteDelta{UserToken: "u1", MovieSlug: "d", OptionChosen: 2, CreatedAt: 500})
+
+	var slugs []string
+	for offset := 0; offset < 4; offset += 2 {
+		page, total, err := rm.UserVotes("u1", 2, offset)
+		if err != nil {
+			t.Fatalf("UserVotes: %v", err)
+		}
+		if total != 3 {
+			t.Errorf("offset %d: total %d, want 3", offset, total)
+		}
+		for _, record := range page {
+			slugs = append(slugs, fmt.Sprintf("%s:%d", record.MovieSlug, record.OptionChosen))
+		}
+	}
+
+	want := []string{"d:2", "a:3", "c:1"}
+	if fmt.Sprint(slugs) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", slugs, want)
+	}
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// RevokeToken - Record a revoked user token (revoking twice keeps the first time and reason)
+func RevokeToken(db *sql.DB, userToken, reason string) error {
+	_, err := db.Exec(`
+		INSERT INTO revoked_tokens (user_token, revoked_at, reason) VALUES (?, ?, ?)
+		ON CONFLICT(user_token) DO NOTHING
+	`, userToken, time.Now().Unix(), reason)
+	return err
+}
+
+// LoadRevokedTokens - Every revoked user token, for the in-memory check in auth
+func LoadRevokedTokens(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT user_token FROM revoked_tokens`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// RemoveUserVotes - Delete every vote by a token and drop any still queued or held, then refresh caches
+func (rm *ResponseManager) RemoveUserVotes(userToken string) (int, error) {
+	// One critical section with the flusher, so no vote by this token lands after the delete
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	// Requests that got past the token check before revocation can still enqueue - the flusher drops those
+	rm.retractedTokens[userToken] = true
+
+	// Everything waiting goes back to held in order, minus this token's votes
+	waiting := rm.held
+drain:
+	for {
+		select {
+		case vote := <-rm.newVotes:
+			waiting = append(waiting, vote)
+		default:
+			break drain
+		}
+	}
+	rm.held = rm.dropRetracted(waiting)
+
+	slugs, err := rm.store.GetUserVoteSlugs(userToken)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := make(map[string][5]int, len(slugs))
+	for _, slug := range slugs {
+		deleted, err := rm.store.DeleteVote(userToken, slug)
+		if err != nil {
+			rm.notifyFlushListeners(removed)
+			return len(removed), err
+		}
+		if deleted {
+			rm.cache.Invalidate(slug)
+			removed[slug] = [5]int{}
+		}
+	}
+
+	rm.notifyFlushListeners(removed)
+	log.Printf("🧹 Removed %d votes by revoked token %s", len(removed), userToken)
+	return len(removed), nil
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// schemaStatements - Tables owned by the vote pipeline (safe to run on every start)
+var schemaStatements = []string{
+	`CREATE TABLE IF NOT EXISTS user_responses (
+		user_token TEXT NOT NULL,
+		movie_slug TEXT NOT NULL,
+		option_chosen INTEGER NOT NULL,
+		PRIMARY KEY (user_token, movie_slug)
+	)`,
+	`CREATE TABLE IF NOT EXISTS movie_responses (
+		movie_slug TEXT PRIMARY KEY,
+		option_0 INTEGER NOT NULL DEFAULT 0,
+		option_1 INTEGER NOT NULL DEFAULT 0,
+		option_2 INTEGER NOT NULL DEFAULT 0,
+		option_3 INTEGER NOT NULL DEFAULT 0,
+		total_votes INTEGER NOT NULL DEFAULT 0
+	)`,
+	// Accepted votes wait here until the flusher commits them
+	`CREATE TABLE IF NOT EXISTS vote_journal (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_token TEXT NOT NULL,
+		movie_slug TEXT NOT NULL,
+		option_chosen INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	)`,
+	// Per-movie vote changes grouped by hour and day, for rating history
+	`CREATE TABLE IF NOT EXISTS movie_vote_buckets (
+		movie_slug TEXT NOT NULL,
+		granularity TEXT NOT NULL,
+		bucket_start INTEGER NOT NULL,
+		option_0 INTEGER NOT NULL DEFAULT 0,
+		option_1 INTEGER NOT NULL DEFAULT 0,
+		option_2 INTEGER NOT NULL DEFAULT 0,
+		option_3 INTEGER NOT NULL DEFAULT 0,
+		total_votes INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (movie_slug, granularity, bucket_start)
+	)`,
+	// IP/user-agent pairs caught voting with bursts of new tokens
+	`CREATE TABLE IF NOT EXISTS vote_flags (
+		client_ip TEXT NOT NULL,
+		user_agent TEXT NOT NULL,
+		flagged_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		new_tokens INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (client_ip, user_agent)
+	)`,
+	// Votes that failed to apply even on their own, kept for inspection and replay
+	`CREATE TABLE IF NOT EXISTS vote_dead_letters (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		journal_id INTEGER NOT NULL,
+		user_token TEXT NOT NULL,
+		movie_slug TEXT NOT NULL,
+		option_chosen INTEGER NOT NULL,
+		retract INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		client_ip TEXT NOT NULL DEFAULT '',
+		user_agent TEXT NOT NULL DEFAULT '',
+		token_created_at INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL,
+		failed_at INTEGER NOT NULL
+	)`,
+	// User tokens refused by the token middleware, loaded into memory at startup
+	`CREATE TABLE IF NOT EXISTS revoked_tokens (
+		user_token TEXT PRIMARY KEY,
+		revoked_at INTEGER NOT NULL,
+		reason TEXT NOT NULL DEFAULT ''
+	)`,
+}
+
+// columnMigration - Column added to an existing table after it first shipped
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+var columnMigrations = []columnMigration{
+	{"vote_journal", "retract", "INTEGER NOT NULL DEFAULT 0"},
+	{"vote_journal", "client_ip", "TEXT NOT NULL DEFAULT ''"},
+	{"vote_journal", "user_agent", "TEXT NOT NULL DEFAULT ''"},
+	{"vote_journal", "token_created_at", "INTEGER NOT NULL DEFAULT 0"},
+	{"user_responses", "voted_at", "INTEGER NOT NULL DEFAULT 0"},
+	{"user_responses", "client_ip", "TEXT NOT NULL DEFAULT ''"},
+	{"user_responses", "user_agent", "TEXT NOT NULL DEFAULT ''"},
+	{"user_responses", "token_created_at", "INTEGER NOT NULL DEFAULT 0"},
+	{"user_responses", "quarantined", "INTEGER NOT NULL DEFAULT 0"},
+	// Set when an admin approves a quarantined vote, so the abuse scan leaves it alone
+	{"user_responses", "reviewed_at", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// indexStatements - Run after column migrations so indexed columns exist
+var indexStatements = []string{
+	`CREATE INDEX IF NOT EXISTS idx_user_responses_voted_at ON user_responses (voted_at)`,
+}
+
+// ensureSchema - Create missing vote pipeline tables and columns
+func ensureSchema(db *sql.DB) error {
+	for _, stmt := range schemaStatements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("schema migration failed: %w", err)
+		}
+	}
+
+	for _, m := range columnMigrations {
+		exists, err := columnExists(db, m.table, m.column)
+		if err != nil {
+			return fmt.Errorf("schema inspection failed for %s: %w", m.table, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+			return fmt.Errorf("adding %s.%s failed: %w", m.table, m.column, err)
+		}
+	}
+
+	for _, stmt := range indexStatements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("index migration failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// columnExists - Check PRAGMA table_info for a column
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrendingWindow - How far back votes count and how fast they decay
+type TrendingWindow struct {
+	Span     time.Duration
+	HalfLife time.Duration
+}
+
+// TrendingWindows - Supported ?window= values
+var TrendingWindows = map[string]TrendingWindow{
+	"24h": {Span: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	"7d":  {Span: 7 * 24 * time.Hour, HalfLife: 48 * time.Hour},
+}
+
+const (
+	trendingRefreshInterval = 5 * time.Minute
+	trendingMaxMovies       = 200 // Ranked movies kept per window
+)
+
+type TrendingMovie struct {
+	Slug  string
+	Score float64
+}
+
+// TrendingRanker - Scores movies by recent vote volume and positivity
+type TrendingRanker struct {
+	db       *sql.DB
+	mu       sync.RWMutex
+	rankings map[string][]TrendingMovie // window -> best first
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+var TrendingRankerInstance *TrendingRanker
+
+func InitTrendingRanker(db *sql.DB) {
+	TrendingRankerInstance = &TrendingRanker{
+		db:       db,
+		rankings: make(map[string][]TrendingMovie),
+		stop:     make(chan struct{}),
+	}
+
+	TrendingRankerInstance.Refresh()
+	go TrendingRankerInstance.periodicRefresh()
+}
+
+// Ranked - Current ranking for a window, best first
+func (tr *TrendingRanker) Ranked(window string) []TrendingMovie {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return tr.rankings[window]
+}
+
+// Refresh - Recompute every window from the hourly vote buckets
+func (tr *TrendingRanker) Refresh() {
+	now := time.Now()
+	rankings := make(map[string][]TrendingMovie, len(TrendingWindows))
+
+	for name, window := range TrendingWindows {
+		ranked, err := tr.score(window, now)
+		if err != nil {
+			log.Printf("⚠️ Failed to compute %s trending: %v", name, err)
+			continue
+		}
+		rankings[name] = ranked
+	}
+
+	tr.mu.Lock()
+	for name, ranked := range rankings {
+		tr.rankings[name] = ranked
+	}
+	tr.mu.Unlock()
+}
+
+// score - Sum of decayed bucket activity weighted by how positive the votes were
+func (tr *TrendingRanker) score(window TrendingWindow, now time.Time) ([]TrendingMovie, error) {
+	rows, err := tr.db.Query(`
+		SELECT movie_slug, bucket_start, option_0, option_1, option_2, option_3
+		FROM movie_vote_buckets
+		WHERE granularity = ? AND bucket_start >= ?
+	`, GranularityHour, BucketStart(now.Add(-window.Span), GranularityHour).Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := make(map[string]float64)
+	for rows.Next() {
+		var slug string
+		var start int64
+		var options [4]int
+		if err := rows.Scan(&slug, &start, &options[0], &options[1], &options[2], &options[3]); err != nil {
+			continue
+		}
+
+		// Only votes cast count as activity; moved/retracted votes show up as negatives
+		var cast, positivity float64
+		for i, n := range options {
+			if n > 0 {
+				cast += float64(n)
+				positivity += float64(n) * float64(i) / 3
+			}
+		}
+		if cast == 0 {
+			continue
+		}
+		positivity /= cast
+
+		age := now.Sub(time.Unix(start, 0))
+		decay := math.Pow(0.5, age.Hours()/window.HalfLife.Hours())
+		scores[slug] += decay * cast * (0.5 + 0.5*positivity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ranked := make([]TrendingMovie, 0, len(scores))
+	for slug, score := range scores {
+		ranked = append(ranked, TrendingMovie{Slug: slug, Score: score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Slug < ranked[j].Slug
+	})
+	if len(ranked) > trendingMaxMovies {
+		ranked = ranked[:trendingMaxMovies]
+	}
+	return ranked, nil
+}
+
+func (tr *TrendingRanker) periodicRefresh() {
+	ticker := time.NewTicker(trendingRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tr.Refresh()
+		case <-tr.stop:
+			return
+		}
+	}
+}
+
+// Shutdown - Stop the background refresh
+func (tr *TrendingRanker) Shutdown() {
+	tr.stopOnce.Do(func() { close(tr.stop) })
+}
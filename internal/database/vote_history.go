@@ -0,0 +1,78 @@
+package database
+
+import "time"
+
+// Bucket granularities written by the flusher
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+var bucketGranularities = []string{GranularityHour, GranularityDay}
+
+type bucketKey struct {
+	movieSlug   string
+	granularity string
+	start       int64
+}
+
+// VoteBucket - Net vote changes for a movie within one hour or day (UTC)
+type VoteBucket struct {
+	Start   time.Time
+	Option0 int
+	Option1 int
+	Option2 int
+	Option3 int
+	Total   int
+}
+
+func newBucketKey(movieSlug, granularity string, createdAt int64) bucketKey {
+	if createdAt == 0 {
+		createdAt = time.Now().Unix()
+	}
+	return bucketKey{
+		movieSlug:   movieSlug,
+		granularity: granularity,
+		start:       BucketStart(time.Unix(createdAt, 0), granularity).Unix(),
+	}
+}
+
+// BucketStart - Truncate a time to the start of its bucket in UTC
+func BucketStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == GranularityDay {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// GetVoteHistory - Buckets for a movie between from and to (inclusive), oldest first
+func (rm *ResponseManager) GetVoteHistory(movieSlug, granularity string, from, to time.Time) ([]VoteBucket, error) {
+	db, err := rm.sqlDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT bucket_start, option_0, option_1, option_2, option_3, total_votes
+		FROM movie_vote_buckets
+		WHERE movie_slug = ? AND granularity = ? AND bucket_start BETWEEN ? AND ?
+		ORDER BY bucket_start ASC
+	`, movieSlug, granularity, BucketStart(from, granularity).Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []VoteBucket{}
+	for rows.Next() {
+		var bucket VoteBucket
+		var start int64
+		if err := rows.Scan(&start, &bucket.Option0, &bucket.Option1, &bucket.Option2, &bucket.Option3, &bucket.Total); err != nil {
+			continue
+		}
+		bucket.Start = time.Unix(start, 0).UTC()
+		buckets = append(buckets, bucket)
+	}
+	return buckets, rows.Err()
+}
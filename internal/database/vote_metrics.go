@@ -0,0 +1,72 @@
+package database
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Vote pipeline timings, observed by the flusher
+var (
+	flushDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mocktale_vote_flush_duration_seconds",
+		Help:    "Time to flush one batch, including retries and dead-lettering.",
+		Buckets: prometheus.DefBuckets,
+	})
+	flushBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mocktale_vote_flush_batch_size",
+		Help:    "Votes taken off the queue per flush.",
+		Buckets: []float64{1, 10, 50, 100, 250, 500, 1000, 2500, 5000},
+	})
+	commitLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mocktale_vote_commit_duration_seconds",
+		Help:    "Time spent in COMMIT for a flush transaction.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "mocktale_vote_queue_depth",
+			Help: "Votes waiting in the in-memory queue.",
+		}, func() float64 { return float64(voteStats().QueueDepth) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "mocktale_vote_queue_capacity",
+			Help: "Size of the in-memory vote queue.",
+		}, func() float64 { return float64(voteStats().QueueCapacity) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "mocktale_votes_accepted_total",
+			Help: "Votes journaled and queued.",
+		}, func() float64 { return float64(voteStats().Accepted) }),
+		rejectedCounter("buffer_full", func(s VoteStats) int64 { return s.RejectedFull }),
+		rejectedCounter("inactive", func(s VoteStats) int64 { return s.RejectedInactive }),
+		rejectedCounter("persist_failed", func(s VoteStats) int64 { return s.RejectedPersist }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "mocktale_vote_flush_failures_total",
+			Help: "Flush attempts that rolled back.",
+		}, func() float64 { return float64(voteStats().FlushFailures) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "mocktale_votes_dead_lettered_total",
+			Help: "Votes moved to vote_dead_letters.",
+		}, func() float64 { return float64(voteStats().DeadLettered) }),
+		flushDuration,
+		flushBatchSize,
+		commitLatency,
+	)
+}
+
+// rejectedCounter - One reason's series of mocktale_votes_rejected_total
+func rejectedCounter(reason string, value func(VoteStats) int64) prometheus.CounterFunc {
+	return prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name:        "mocktale_votes_rejected_total",
+		Help:        "Votes refused at intake, by reason.",
+		ConstLabels: prometheus.Labels{"reason": reason},
+	}, func() float64 { return float64(value(voteStats())) })
+}
+
+// voteStats - Zero stats until the response manager is running (e.g. CLI commands)
+func voteStats() VoteStats {
+	if ResponseManagerInstance == nil {
+		return VoteStats{}
+	}
+	return ResponseManagerInstance.Stats()
+}
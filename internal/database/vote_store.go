@@ -0,0 +1,144 @@
+package database
+
+import "errors"
+
+// ErrStoreBusy - The store is locked or busy; the flusher retries the batch later instead of dead-lettering it
+var ErrStoreBusy = errors.New("vote store is busy")
+
+// VoteStore - Durable storage behind the ResponseManager pipeline
+type VoteStore interface {
+	// AppendJournal - Persist an accepted vote before it is queued, returning its journal ID
+	AppendJournal(vote VoteDelta) (int64, error)
+	// RemoveJournal - Drop a journal entry for a vote that was never queued
+	RemoveJournal(journalID int64) error
+	// LoadJournal - Every journaled vote not yet applied, oldest first
+	LoadJournal() ([]VoteDelta, error)
+
+	// RecordBatch - Apply collapsed votes and clear their journal entries atomically
+	RecordBatch(batch VoteBatch) (BatchResult, error)
+	// DeadLetter - Set aside a vote that cannot be applied, clearing its journal entry
+	DeadLetter(vote VoteDelta, cause error) error
+	// ListDeadLetters - Newest first, with the total count
+	ListDeadLetters(limit, offset int) ([]DeadLetter, int, error)
+	// LoadDeadLetterVotes - Dead letters as replayable votes, oldest first (all when ids is empty)
+	LoadDeadLetterVotes(ids []int64) ([]DeadLetterVote, error)
+	// RemoveDeadLetter - Drop a dead letter once it has been replayed or superseded
+	RemoveDeadLetter(id int64) error
+
+	// GetCounts - Aggregates for a movie (zero counts when nobody has voted)
+	GetCounts(movieSlug string) (MovieResponseCounts, error)
+	// GetCountsFor - Aggregates for many movies, keyed by slug (movies without votes are absent)
+	GetCountsFor(movieSlugs []string) (map[string]MovieResponseCounts, error)
+	// GetGlobalTotals - Option counts summed over every movie with votes
+	GetGlobalTotals() (GlobalVoteTotals, error)
+	// GetUserVote - The user's recorded vote, if any
+	GetUserVote(userToken, movieSlug string) (StoredVote, bool, error)
+	// GetUserVotes - The user's recorded votes among movieSlugs, keyed by slug
+	GetUserVotes(userToken string, movieSlugs []string) (map[string]StoredVote, error)
+	// GetUserVoteSlugs - Every movie the user has a recorded vote for
+	GetUserVoteSlugs(userToken string) ([]string, error)
+	// ListUserVotes - A page of the user's recorded votes, newest first then by slug, leaving out
+	// movies in skip; the total leaves them out too
+	ListUserVotes(userToken string, skip []string, limit, offset int) ([]UserVoteRecord, int, error)
+	// DeleteVote - Remove a recorded vote and its share of the aggregates right away
+	DeleteVote(userToken, movieSlug string) (bool, error)
+}
+
+// StoredVote - A user's applied vote for one movie
+type StoredVote struct {
+	OptionChosen int
+	Quarantined  bool
+	VotedAt      int64
+}
+
+// UserVoteRecord - One movie in a user's vote list
+type UserVoteRecord struct {
+	MovieSlug    string
+	OptionChosen int
+	VotedAt      int64 // 0 for votes cast before vote times were recorded
+}
+
+// VoteBatch - Votes collapsed to one per (user, movie), plus every journal entry they came from
+type VoteBatch struct {
+	Votes      []VoteDelta
+	JournalIDs []int64
+	Quarantine func(vote VoteDelta) bool
+}
+
+// BatchResult - What a recorded batch changed
+type BatchResult struct {
+	Deltas      map[string][5]int // per movie: option_0..3, total
+	Changed     int
+	Retracted   int
+	Quarantined int
+	Superseded  int // older than the stored vote, e.g. a replayed dead letter
+}
+
+// voteOutcome - What applying one vote does to user_responses and the aggregates
+type voteOutcome struct {
+	write      bool // store the vote (insert or replace)
+	remove     bool // delete the prior vote
+	quarantine bool
+	changed    bool // replaced an existing vote
+	superseded bool // the stored vote is newer - leave it alone
+	change     [5]int
+}
+
+// planVote - Shared by every store so vote changes, retractions and quarantine count the same way
+func planVote(prior StoredVote, hadPrior bool, vote VoteDelta, quarantine bool) voteOutcome {
+	var outcome voteOutcome
+
+	// A vote cast after this one (a replayed dead letter, say) already stands
+	if hadPrior && prior.VotedAt > vote.CreatedAt {
+		return voteOutcome{superseded: true}
+	}
+
+	// Take the prior vote out of the aggregates (quarantined votes were never in)
+	if hadPrior && !prior.Quarantined {
+		if prior.OptionChosen >= 0 && prior.OptionChosen <= 3 {
+			outcome.change[prior.OptionChosen]--
+		}
+		outcome.change[4]--
+	}
+
+	if vote.Retract {
+		// Nothing to take back
+		if !hadPrior {
+			return voteOutcome{}
+		}
+		outcome.remove = true
+		return outcome
+	}
+
+	// Same answer as before - nothing to write
+	if hadPrior && prior.OptionChosen == vote.OptionChosen && prior.Quarantined == quarantine {
+		return voteOutcome{}
+	}
+
+	outcome.write = true
+	outcome.changed = hadPrior
+	outcome.quarantine = quarantine
+	if !quarantine {
+		if vote.OptionChosen >= 0 && vote.OptionChosen <= 3 {
+			outcome.change[vote.OptionChosen]++
+		}
+		outcome.change[4]++
+	}
+	return outcome
+}
+
+// tally - Fold one vote's outcome into the batch result
+func (r *BatchResult) tally(outcome voteOutcome) {
+	if outcome.remove {
+		r.Retracted++
+	}
+	if outcome.changed {
+		r.Changed++
+	}
+	if outcome.write && outcome.quarantine {
+		r.Quarantined++
+	}
+	if outcome.superseded {
+		r.Superseded++
+	}
+}
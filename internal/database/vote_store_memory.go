@@ -0,0 +1,297 @@
+package database
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryVoteStore - VoteStore kept in maps, for exercising the pipeline without SQLite
+type MemoryVoteStore struct {
+	mu          sync.Mutex
+	journal     map[int64]VoteDelta
+	nextJournal int64
+	votes       map[pendingKey]StoredVote
+	counts      map[string]MovieResponseCounts
+	deadLetters []memoryDeadLetter
+	nextLetter  int64
+}
+
+type memoryDeadLetter struct {
+	letter DeadLetter
+	vote   VoteDelta
+}
+
+func NewMemoryVoteStore() *MemoryVoteStore {
+	return &MemoryVoteStore{
+		journal: make(map[int64]VoteDelta),
+		votes:   make(map[pendingKey]StoredVote),
+		counts:  make(map[string]MovieResponseCounts),
+	}
+}
+
+func (s *MemoryVoteStore) AppendJournal(vote VoteDelta) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextJournal++
+	vote.JournalID = s.nextJournal
+	s.journal[vote.JournalID] = vote
+	return vote.JournalID, nil
+}
+
+func (s *MemoryVoteStore) RemoveJournal(journalID int64) error {
+	s.mu.Lock()
+	delete(s.journal, journalID)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryVoteStore) LoadJournal() ([]VoteDelta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	votes := make([]VoteDelta, 0, len(s.journal))
+	for _, vote := range s.journal {
+		votes = append(votes, vote)
+	}
+	sort.Slice(votes, func(i, j int) bool { return votes[i].JournalID < votes[j].JournalID })
+	return votes, nil
+}
+
+// RecordBatch - Applied under one lock, so readers never see half a batch
+func (s *MemoryVoteStore) RecordBatch(batch VoteBatch) (BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := BatchResult{}
+	deltas := newAggregateDeltas()
+
+	for _, vote := range batch.Votes {
+		key := pendingKey{vote.UserToken, vote.MovieSlug}
+		prior, hadPrior := s.votes[key]
+
+		quarantine := !vote.Retract && batch.Quarantine != nil && batch.Quarantine(vote)
+		outcome := planVote(prior, hadPrior, vote, quarantine)
+
+		switch {
+		case outcome.remove:
+			delete(s.votes, key)
+		case outcome.write:
+			s.votes[key] = StoredVote{
+				OptionChosen: vote.OptionChosen,
+				Quarantined:  outcome.quarantine,
+				VotedAt:      vote.CreatedAt,
+			}
+		default:
+			// Nothing to write, though a superseded vote still shows in the result
+			result.tally(outcome)
+			continue
+		}
+
+		result.tally(outcome)
+		deltas.add(vote.MovieSlug, vote.CreatedAt, outcome.change)
+	}
+
+	for movieSlug, delta := range deltas.movies {
+		s.counts[movieSlug] = applyCountDelta(s.counts[movieSlug], delta)
+	}
+	for _, journalID := range batch.JournalIDs {
+		delete(s.journal, journalID)
+	}
+
+	result.Deltas = deltas.movies
+	return result, nil
+}
+
+func (s *MemoryVoteStore) DeadLetter(vote VoteDelta, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextLetter++
+	s.deadLetters = append(s.deadLetters, memoryDeadLetter{
+		letter: DeadLetter{
+			ID:           s.nextLetter,
+			JournalID:    vote.JournalID,
+			UserToken:    vote.UserToken,
+			MovieSlug:    vote.MovieSlug,
+			OptionChosen: vote.OptionChosen,
+			Retract:      vote.Retract,
+			CreatedAt:    time.Unix(vote.CreatedAt, 0).UTC(),
+			Error:        cause.Error(),
+			FailedAt:     time.Now().UTC(),
+		},
+		vote: vote,
+	})
+	delete(s.journal, vote.JournalID)
+	return nil
+}
+
+func (s *MemoryVoteStore) ListDeadLetters(limit, offset int) ([]DeadLetter, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letters := []DeadLetter{}
+	for i := len(s.deadLetters) - 1 - offset; i >= 0 && len(letters) < limit; i-- {
+		letters = append(letters, s.deadLetters[i].letter)
+	}
+	return letters, len(s.deadLetters), nil
+}
+
+func (s *MemoryVoteStore) LoadDeadLetterVotes(ids []int64) ([]DeadLetterVote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var letters []DeadLetterVote
+	for _, dl := range s.deadLetters {
+		if len(ids) == 0 || wanted[dl.letter.ID] {
+			// Queued again from scratch, the way the SQLite store reads it back
+			vote := dl.vote
+			vote.JournalID = 0
+			letters = append(letters, DeadLetterVote{ID: dl.letter.ID, Vote: vote})
+		}
+	}
+	return letters, nil
+}
+
+func (s *MemoryVoteStore) RemoveDeadLetter(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, dl := range s.deadLetters {
+		if dl.letter.ID == id {
+			s.deadLetters = append(s.deadLetters[:i], s.deadLetters[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *MemoryVoteStore) GetCounts(movieSlug string) (MovieResponseCounts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[movieSlug], nil
+}
+
+func (s *MemoryVoteStore) GetCountsFor(movieSlugs []string) (map[string]MovieResponseCounts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]MovieResponseCounts, len(movieSlugs))
+	for _, slug := range movieSlugs {
+		if c, ok := s.counts[slug]; ok {
+			counts[slug] = c
+		}
+	}
+	return counts, nil
+}
+
+func (s *MemoryVoteStore) GetGlobalTotals() (GlobalVoteTotals, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var totals GlobalVoteTotals
+	for _, c := range s.counts {
+		if c.Total <= 0 {
+			continue
+		}
+		totals.Option0 += int64(c.Option0)
+		totals.Option1 += int64(c.Option1)
+		totals.Option2 += int64(c.Option2)
+		totals.Option3 += int64(c.Option3)
+		totals.MoviesWithVotes++
+	}
+	return totals, nil
+}
+
+func (s *MemoryVoteStore) GetUserVote(userToken, movieSlug string) (StoredVote, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vote, ok := s.votes[pendingKey{userToken, movieSlug}]
+	return vote, ok, nil
+}
+
+func (s *MemoryVoteStore) GetUserVotes(userToken string, movieSlugs []string) (map[string]StoredVote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	votes := make(map[string]StoredVote, len(movieSlugs))
+	for _, slug := range movieSlugs {
+		if vote, ok := s.votes[pendingKey{userToken, slug}]; ok {
+			votes[slug] = vote
+		}
+	}
+	return votes, nil
+}
+
+func (s *MemoryVoteStore) GetUserVoteSlugs(userToken string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var slugs []string
+	for key := range s.votes {
+		if key.userToken == userToken {
+			slugs = append(slugs, key.movieSlug)
+		}
+	}
+	return slugs, nil
+}
+
+func (s *MemoryVoteStore) ListUserVotes(userToken string, skip []string, limit, offset int) ([]UserVoteRecord, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	skipped := make(map[string]bool, len(skip))
+	for _, slug := range skip {
+		skipped[slug] = true
+	}
+
+	records := []UserVoteRecord{}
+	for key, vote := range s.votes {
+		if key.userToken == userToken && !skipped[key.movieSlug] {
+			records = append(records, UserVoteRecord{MovieSlug: key.movieSlug, OptionChosen: vote.OptionChosen, VotedAt: vote.VotedAt})
+		}
+	}
+	sortUserVotes(records)
+
+	total := len(records)
+	if offset >= total {
+		return []UserVoteRecord{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return records[offset:end], total, nil
+}
+
+func (s *MemoryVoteStore) DeleteVote(userToken, movieSlug string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pendingKey{userToken, movieSlug}
+	prior, ok := s.votes[key]
+	if !ok {
+		return false, nil
+	}
+	delete(s.votes, key)
+
+	outcome := planVote(prior, true, VoteDelta{UserToken: userToken, MovieSlug: movieSlug, Retract: true, CreatedAt: prior.VotedAt}, false)
+	s.counts[movieSlug] = applyCountDelta(s.counts[movieSlug], outcome.change)
+	return true, nil
+}
+
+// applyCountDelta - Add an option_0..3/total delta to counts
+func applyCountDelta(counts MovieResponseCounts, delta [5]int) MovieResponseCounts {
+	counts.Option0 += delta[0]
+	counts.Option1 += delta[1]
+	counts.Option2 += delta[2]
+	counts.Option3 += delta[3]
+	counts.Total += delta[4]
+	return counts
+}
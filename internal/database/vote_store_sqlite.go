@@ -0,0 +1,468 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLiteVoteStore - VoteStore on the vote_journal, user_responses and movie_responses tables
+type SQLiteVoteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteVoteStore(db *sql.DB) *SQLiteVoteStore {
+	return &SQLiteVoteStore{db: db}
+}
+
+func (s *SQLiteVoteStore) AppendJournal(vote VoteDelta) (int64, error) {
+	result, err := s.db.Exec(`
+		INSERT INTO vote_journal (user_token, movie_slug, option_chosen, retract, created_at,
+			client_ip, user_agent, token_created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, vote.UserToken, vote.MovieSlug, vote.OptionChosen, vote.Retract, vote.CreatedAt,
+		vote.ClientIP, vote.UserAgent, vote.TokenCreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (s *SQLiteVoteStore) RemoveJournal(journalID int64) error {
+	_, err := s.db.Exec(`DELETE FROM vote_journal WHERE id = ?`, journalID)
+	return err
+}
+
+func (s *SQLiteVoteStore) LoadJournal() ([]VoteDelta, error) {
+	rows, err := s.db.Query(`
+		SELECT id, user_token, movie_slug, option_chosen, retract, created_at,
+		       client_ip, user_agent, token_created_at
+		FROM vote_journal ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var votes []VoteDelta
+	for rows.Next() {
+		var vote VoteDelta
+		if err := rows.Scan(&vote.JournalID, &vote.UserToken, &vote.MovieSlug, &vote.OptionChosen, &vote.Retract, &vote.CreatedAt,
+			&vote.ClientIP, &vote.UserAgent, &vote.TokenCreatedAt); err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+// RecordBatch - One transaction for the whole batch; any failure rolls everything back
+func (s *SQLiteVoteStore) RecordBatch(batch VoteBatch) (BatchResult, error) {
+	result, err := s.recordBatch(batch)
+	return result, busyError(err)
+}
+
+func (s *SQLiteVoteStore) recordBatch(batch VoteBatch) (BatchResult, error) {
+	result := BatchResult{}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, fmt.Errorf("begin transaction: %w", err)
+	}
+	// No-op once committed
+	defer tx.Rollback()
+
+	priorVoteStmt, err := tx.Prepare(`
+		SELECT option_chosen, quarantined, voted_at FROM user_responses
+		WHERE user_token = ? AND movie_slug = ?
+	`)
+	if err != nil {
+		return result, fmt.Errorf("prepare prior vote statement: %w", err)
+	}
+	defer priorVoteStmt.Close()
+
+	userResponseStmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO user_responses (user_token, movie_slug, option_chosen,
+			voted_at, client_ip, user_agent, token_created_at, quarantined)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return result, fmt.Errorf("prepare user response statement: %w", err)
+	}
+	defer userResponseStmt.Close()
+
+	deleteResponseStmt, err := tx.Prepare(`
+		DELETE FROM user_responses WHERE user_token = ? AND movie_slug = ?
+	`)
+	if err != nil {
+		return result, fmt.Errorf("prepare delete response statement: %w", err)
+	}
+	defer deleteResponseStmt.Close()
+
+	journalStmt, err := tx.Prepare(`DELETE FROM vote_journal WHERE id = ?`)
+	if err != nil {
+		return result, fmt.Errorf("prepare journal statement: %w", err)
+	}
+	defer journalStmt.Close()
+
+	deltas := newAggregateDeltas()
+
+	for _, vote := range batch.Votes {
+		var prior StoredVote
+		hadPrior := true
+		if err := priorVoteStmt.QueryRow(vote.UserToken, vote.MovieSlug).Scan(&prior.OptionChosen, &prior.Quarantined, &prior.VotedAt); err != nil {
+			if err != sql.ErrNoRows {
+				return result, fmt.Errorf("look up prior vote for %s: %w", vote.MovieSlug, err)
+			}
+			hadPrior = false
+		}
+
+		quarantine := !vote.Retract && batch.Quarantine != nil && batch.Quarantine(vote)
+		outcome := planVote(prior, hadPrior, vote, quarantine)
+
+		switch {
+		case outcome.remove:
+			if _, err := deleteResponseStmt.Exec(vote.UserToken, vote.MovieSlug); err != nil {
+				return result, fmt.Errorf("retract user response for %s: %w", vote.MovieSlug, err)
+			}
+		case outcome.write:
+			_, err := userResponseStmt.Exec(vote.UserToken, vote.MovieSlug, vote.OptionChosen,
+				vote.CreatedAt, vote.ClientIP, vote.UserAgent, vote.TokenCreatedAt, outcome.quarantine)
+			if err != nil {
+				return result, fmt.Errorf("update user response for %s: %w", vote.MovieSlug, err)
+			}
+		default:
+			// Nothing to write, though a superseded vote still shows in the result
+			result.tally(outcome)
+			continue
+		}
+
+		result.tally(outcome)
+		deltas.add(vote.MovieSlug, vote.CreatedAt, outcome.change)
+	}
+
+	if err := deltas.write(tx); err != nil {
+		return result, err
+	}
+
+	// Journal entries are cleared in the same transaction that applies them
+	for _, journalID := range batch.JournalIDs {
+		if _, err := journalStmt.Exec(journalID); err != nil {
+			return result, fmt.Errorf("clear journal entry %d: %w", journalID, err)
+		}
+	}
+
+	commitStarted := time.Now()
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit: %w", err)
+	}
+	commitLatency.Observe(time.Since(commitStarted).Seconds())
+
+	result.Deltas = deltas.movies
+	return result, nil
+}
+
+// DeadLetter - Move a vote out of the journal into vote_dead_letters
+func (s *SQLiteVoteStore) DeadLetter(vote VoteDelta, cause error) error {
+	return busyError(s.deadLetter(vote, cause))
+}
+
+func (s *SQLiteVoteStore) deadLetter(vote VoteDelta, cause error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO vote_dead_letters (journal_id, user_token, movie_slug, option_chosen, retract,
+			created_at, client_ip, user_agent, token_created_at, error, failed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, vote.JournalID, vote.UserToken, vote.MovieSlug, vote.OptionChosen, vote.Retract,
+		vote.CreatedAt, vote.ClientIP, vote.UserAgent, vote.TokenCreatedAt, cause.Error(), time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	if vote.JournalID != 0 {
+		if _, err := tx.Exec(`DELETE FROM vote_journal WHERE id = ?`, vote.JournalID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListDeadLetters - Newest first
+func (s *SQLiteVoteStore) ListDeadLetters(limit, offset int) ([]DeadLetter, int, error) {
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM vote_dead_letters`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, journal_id, user_token, movie_slug, option_chosen, retract, created_at, error, failed_at
+		FROM vote_dead_letters ORDER BY id DESC LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	letters := []DeadLetter{}
+	for rows.Next() {
+		var letter DeadLetter
+		var createdAt, failedAt int64
+		if err := rows.Scan(&letter.ID, &letter.JournalID, &letter.UserToken, &letter.MovieSlug,
+			&letter.OptionChosen, &letter.Retract, &createdAt, &letter.Error, &failedAt); err != nil {
+			continue
+		}
+		letter.CreatedAt = time.Unix(createdAt, 0).UTC()
+		letter.FailedAt = time.Unix(failedAt, 0).UTC()
+		letters = append(letters, letter)
+	}
+	return letters, total, rows.Err()
+}
+
+func (s *SQLiteVoteStore) LoadDeadLetterVotes(ids []int64) ([]DeadLetterVote, error) {
+	query := `
+		SELECT id, user_token, movie_slug, option_chosen, retract, created_at,
+		       client_ip, user_agent, token_created_at
+		FROM vote_dead_letters`
+	args := []interface{}{}
+	if len(ids) > 0 {
+		placeholders := make([]string, len(ids))
+		for i, id := range ids {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		query += " WHERE id IN (" + strings.Join(placeholders, ",") + ")"
+	}
+	query += " ORDER BY id ASC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var letters []DeadLetterVote
+	for rows.Next() {
+		var l DeadLetterVote
+		if err := rows.Scan(&l.ID, &l.Vote.UserToken, &l.Vote.MovieSlug, &l.Vote.OptionChosen, &l.Vote.Retract,
+			&l.Vote.CreatedAt, &l.Vote.ClientIP, &l.Vote.UserAgent, &l.Vote.TokenCreatedAt); err != nil {
+			return nil, err
+		}
+		letters = append(letters, l)
+	}
+	return letters, rows.Err()
+}
+
+func (s *SQLiteVoteStore) RemoveDeadLetter(id int64) error {
+	_, err := s.db.Exec(`DELETE FROM vote_dead_letters WHERE id = ?`, id)
+	return err
+}
+
+func (s *SQLiteVoteStore) GetCounts(movieSlug string) (MovieResponseCounts, error) {
+	var counts MovieResponseCounts
+	err := s.db.QueryRow(`
+		SELECT option_0, option_1, option_2, option_3, total_votes
+		FROM movie_responses WHERE movie_slug = ?
+	`, movieSlug).Scan(&counts.Option0, &counts.Option1, &counts.Option2, &counts.Option3, &counts.Total)
+	if err == sql.ErrNoRows {
+		return MovieResponseCounts{}, nil
+	}
+	return counts, err
+}
+
+// GetCountsFor - One IN query for the whole list
+func (s *SQLiteVoteStore) GetCountsFor(movieSlugs []string) (map[string]MovieResponseCounts, error) {
+	counts := make(map[string]MovieResponseCounts, len(movieSlugs))
+	if len(movieSlugs) == 0 {
+		return counts, nil
+	}
+
+	placeholders, args := inPlaceholders(movieSlugs)
+	rows, err := s.db.Query(`
+		SELECT movie_slug, option_0, option_1, option_2, option_3, total_votes
+		FROM movie_responses WHERE movie_slug IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slug string
+		var c MovieResponseCounts
+		if err := rows.Scan(&slug, &c.Option0, &c.Option1, &c.Option2, &c.Option3, &c.Total); err != nil {
+			return nil, err
+		}
+		counts[slug] = c
+	}
+	return counts, rows.Err()
+}
+
+func (s *SQLiteVoteStore) GetGlobalTotals() (GlobalVoteTotals, error) {
+	var totals GlobalVoteTotals
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(option_0), 0), COALESCE(SUM(option_1), 0),
+		       COALESCE(SUM(option_2), 0), COALESCE(SUM(option_3), 0), COUNT(*)
+		FROM movie_responses WHERE total_votes > 0
+	`).Scan(&totals.Option0, &totals.Option1, &totals.Option2, &totals.Option3, &totals.MoviesWithVotes)
+	return totals, err
+}
+
+func (s *SQLiteVoteStore) GetUserVote(userToken, movieSlug string) (StoredVote, bool, error) {
+	var vote StoredVote
+	err := s.db.QueryRow(`
+		SELECT option_chosen, quarantined, voted_at FROM user_responses
+		WHERE user_token = ? AND movie_slug = ?
+	`, userToken, movieSlug).Scan(&vote.OptionChosen, &vote.Quarantined, &vote.VotedAt)
+	if err == sql.ErrNoRows {
+		return StoredVote{}, false, nil
+	}
+	if err != nil {
+		return StoredVote{}, false, err
+	}
+	return vote, true, nil
+}
+
+// GetUserVotes - One IN query for the whole list
+func (s *SQLiteVoteStore) GetUserVotes(userToken string, movieSlugs []string) (map[string]StoredVote, error) {
+	votes := make(map[string]StoredVote, len(movieSlugs))
+	if len(movieSlugs) == 0 {
+		return votes, nil
+	}
+
+	placeholders, slugArgs := inPlaceholders(movieSlugs)
+	rows, err := s.db.Query(`
+		SELECT movie_slug, option_chosen, quarantined, voted_at FROM user_responses
+		WHERE user_token = ? AND movie_slug IN (`+placeholders+`)
+	`, append([]interface{}{userToken}, slugArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slug string
+		var vote StoredVote
+		if err := rows.Scan(&slug, &vote.OptionChosen, &vote.Quarantined, &vote.VotedAt); err != nil {
+			return nil, err
+		}
+		votes[slug] = vote
+	}
+	return votes, rows.Err()
+}
+
+func (s *SQLiteVoteStore) GetUserVoteSlugs(userToken string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT movie_slug FROM user_responses WHERE user_token = ?`, userToken)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
+
+func (s *SQLiteVoteStore) ListUserVotes(userToken string, skip []string, limit, offset int) ([]UserVoteRecord, int, error) {
+	where := "user_token = ?"
+	args := []interface{}{userToken}
+	if len(skip) > 0 {
+		placeholders, skipArgs := inPlaceholders(skip)
+		where += " AND movie_slug NOT IN (" + placeholders + ")"
+		args = append(args, skipArgs...)
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM user_responses WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT movie_slug, option_chosen, voted_at FROM user_responses
+		WHERE `+where+`
+		ORDER BY voted_at DESC, movie_slug ASC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	records := []UserVoteRecord{}
+	for rows.Next() {
+		var record UserVoteRecord
+		if err := rows.Scan(&record.MovieSlug, &record.OptionChosen, &record.VotedAt); err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+	return records, total, rows.Err()
+}
+
+// DeleteVote - Remove the vote and its aggregate contribution in one transaction
+func (s *SQLiteVoteStore) DeleteVote(userToken, movieSlug string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var prior StoredVote
+	err = tx.QueryRow(`
+		SELECT option_chosen, quarantined, voted_at FROM user_responses
+		WHERE user_token = ? AND movie_slug = ?
+	`, userToken, movieSlug).Scan(&prior.OptionChosen, &prior.Quarantined, &prior.VotedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_responses WHERE user_token = ? AND movie_slug = ?`, userToken, movieSlug); err != nil {
+		return false, err
+	}
+
+	// Bucketed now, the same as a retraction through the queue
+	outcome := planVote(prior, true, VoteDelta{UserToken: userToken, MovieSlug: movieSlug, Retract: true, CreatedAt: prior.VotedAt}, false)
+	deltas := newAggregateDeltas()
+	deltas.add(movieSlug, time.Now().Unix(), outcome.change)
+	if err := deltas.write(tx); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// busyError - Mark SQLITE_BUSY/SQLITE_LOCKED as ErrStoreBusy so the flusher waits instead of dead-lettering
+func busyError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked) {
+		return fmt.Errorf("%w: %v", ErrStoreBusy, err)
+	}
+	return err
+}
+
+// inPlaceholders - "?,?,?" and matching args for an IN clause
+func inPlaceholders(values []string) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = "?"
+		args[i] = value
+	}
+	return strings.Join(placeholders, ","), args
+}
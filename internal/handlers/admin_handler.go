@@ -88,6 +88,9 @@ func AdminCreateMovie(c *gin.Context) {
 	// Initialize movie responses
 	_, _ = database.DB.Exec(`INSERT OR IGNORE INTO movie_responses (movie_slug) VALUES (?)`, request.Slug)
 
+	// Make the movie votable right away
+	database.MovieCatalogInstance.Set(request.Slug, request.IsReleased)
+
 	c.JSON(http.StatusCreated, models.MovieResponse{
 		Success: true,
 		Message: "Movie created successfully",
@@ -171,6 +174,8 @@ func AdminUpdateMovie(c *gin.Context) {
 		return
 	}
 
+	database.MovieCatalogInstance.Set(slug, request.IsReleased)
+
 	c.JSON(http.StatusOK, models.MovieResponse{
 		Success: true,
 		Message: "Movie updated successfully",
@@ -417,4 +422,12 @@ func AdminResetHomepage(c *gin.Context) {
 		Message: "Homepage reset to default successfully",
 		Data:    defaultSections,
 	})
-}
\ No newline at end of file
+}
+
+// AdminGetVoteStats - Vote intake counters (accepted / rejected / queue depth)
+func AdminGetVoteStats(c *gin.Context) {
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data:    database.ResponseManagerInstance.Stats(),
+	})
+}
@@ -0,0 +1,64 @@
+// handlers/admin_tokens_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"movie-api/internal/auth"
+	"movie-api/internal/database"
+	"movie-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRevokeToken - Reject a user token from now on, optionally removing its votes
+func AdminRevokeToken(c *gin.Context) {
+	var request struct {
+		UserToken    string `json:"user_token" binding:"required"`
+		Reason       string `json:"reason"`
+		RetractVotes bool   `json:"retract_votes"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if err := database.RevokeToken(database.DB, request.UserToken, request.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to revoke token: " + err.Error(),
+		})
+		return
+	}
+	// Revoked before votes are removed so the token can't vote again in between
+	auth.RevokeToken(request.UserToken)
+
+	retracted := 0
+	if request.RetractVotes {
+		removed, err := database.ResponseManagerInstance.RemoveUserVotes(request.UserToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.MovieResponse{
+				Success: false,
+				Message: "Token revoked but removing its votes failed: " + err.Error(),
+				Data: map[string]interface{}{
+					"votes_retracted": removed,
+				},
+			})
+			return
+		}
+		retracted = removed
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Message: "Token revoked",
+		Data: map[string]interface{}{
+			"user_token":      request.UserToken,
+			"votes_retracted": retracted,
+		},
+	})
+}
@@ -0,0 +1,255 @@
+// handlers/admin_votes_handler.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"movie-api/internal/database"
+	"movie-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListQuarantinedVotes - Votes held back by abuse heuristics, optionally grouped
+func AdminListQuarantinedVotes(c *gin.Context) {
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		groups, err := database.ResponseManagerInstance.QuarantineGroups(groupBy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.MovieResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, models.MovieResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"group_by": groupBy,
+				"groups":   groups,
+			},
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	filter := database.QuarantineFilter{
+		MovieSlug: c.Query("movie_slug"),
+		ClientIP:  c.Query("client_ip"),
+		UserAgent: c.Query("user_agent"),
+	}
+	if token := c.Query("user_token"); token != "" {
+		filter.UserTokens = []string{token}
+	}
+
+	votes, total, err := database.ResponseManagerInstance.ListQuarantined(filter, limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to fetch quarantined votes: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"votes": votes,
+			"pagination": models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+			},
+		},
+	})
+}
+
+// AdminApproveQuarantinedVotes - Count matching quarantined votes in the public ratings
+func AdminApproveQuarantinedVotes(c *gin.Context) {
+	reviewQuarantinedVotes(c, true)
+}
+
+// AdminDiscardQuarantinedVotes - Delete matching quarantined votes
+func AdminDiscardQuarantinedVotes(c *gin.Context) {
+	reviewQuarantinedVotes(c, false)
+}
+
+func reviewQuarantinedVotes(c *gin.Context, approve bool) {
+	var request struct {
+		database.QuarantineFilter
+		Unflag bool `json:"unflag"` // Also lift the flag on client_ip (and user_agent)
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	if request.Unflag && request.ClientIP == "" {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "unflag requires client_ip",
+		})
+		return
+	}
+
+	reviewed, err := database.ResponseManagerInstance.ReviewQuarantined(request.QuarantineFilter, approve)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrEmptyQuarantineFilter) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.MovieResponse{
+			Success: false,
+			Message: "Failed to review votes: " + err.Error(),
+		})
+		return
+	}
+
+	unflagged := 0
+	if request.Unflag {
+		unflagged, err = database.ResponseManagerInstance.UnflagSource(request.ClientIP, request.UserAgent)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.MovieResponse{
+				Success: false,
+				Message: "Votes reviewed but failed to lift flag: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	message := "Quarantined votes discarded"
+	if approve {
+		message = "Quarantined votes approved"
+	}
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Message: message,
+		Data: map[string]interface{}{
+			"reviewed":  reviewed,
+			"unflagged": unflagged,
+		},
+	})
+}
+
+// AdminRebuildRatings - Recompute movie_responses from user_responses, reporting drift
+func AdminRebuildRatings(c *gin.Context) {
+	var request struct {
+		MovieSlug string `json:"movie_slug"` // Empty rebuilds every movie
+		DryRun    bool   `json:"dry_run"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	drifts, err := database.ResponseManagerInstance.RebuildAggregates(request.MovieSlug, request.DryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to rebuild ratings: " + err.Error(),
+		})
+		return
+	}
+
+	message := "Ratings rebuilt"
+	if request.DryRun {
+		message = "Dry run - no changes written"
+	}
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Message: message,
+		Data: map[string]interface{}{
+			"dry_run": request.DryRun,
+			"drifted": len(drifts),
+			"drift":   drifts,
+		},
+	})
+}
+
+// AdminListDeadLetters - Votes that failed to flush, with the error text
+func AdminListDeadLetters(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	letters, total, err := database.ResponseManagerInstance.ListDeadLetters(limit, (page-1)*limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to fetch dead letters: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"dead_letters": letters,
+			"pagination": models.Pagination{
+				Page:  page,
+				Limit: limit,
+				Total: total,
+			},
+		},
+	})
+}
+
+// AdminReplayDeadLetters - Re-queue dead letters by id, or all of them
+func AdminReplayDeadLetters(c *gin.Context) {
+	var request struct {
+		IDs []int64 `json:"ids"`
+		All bool    `json:"all"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	if len(request.IDs) == 0 && !request.All {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Provide ids or set all to true",
+		})
+		return
+	}
+
+	replayed, superseded, err := database.ResponseManagerInstance.ReplayDeadLetters(request.IDs)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, models.MovieResponse{
+			Success: false,
+			Message: "Replay stopped early: " + err.Error(),
+			Data:    map[string]interface{}{"replayed": replayed, "superseded": superseded},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Message: "Dead letters re-queued",
+		Data:    map[string]interface{}{"replayed": replayed, "superseded": superseded},
+	})
+}
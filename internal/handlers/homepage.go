@@ -11,6 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Movies shown in the computed trending_movies section
+const homepageTrendingLimit = 20
+
 func GetHomepageSections(c *gin.Context) {
 
 	// Get all active sections ordered by display order
@@ -55,6 +58,13 @@ func GetHomepageSections(c *gin.Context) {
 			}
 		}
 		
+		// Trending is computed from votes unless an editor pinned items by hand
+		if section.SectionType == "trending_movies" && len(section.SectionData) == 0 {
+			if items := trendingSectionItems(homepageTrendingLimit); len(items) > 0 {
+				section.SectionData = items
+			}
+		}
+		
 		sections = append(sections, section)
 	}
 
@@ -63,47 +63,25 @@ func GetMovies(c *gin.Context) {
 	var movies []models.Movie
 
 	for rows.Next() {
-		var movie models.Movie
-		var imageURL, bannerURL, description, durationFormatted, ageRatingFormatted, 
-			releaseDate, trailerVideoID, awards, countriesStr, languagesStr, genresStr, 
-			categoriesStr, actorsStr, directorsStr sql.NullString
-		var year sql.NullInt64
-		
-		err := rows.Scan(
-			&movie.Name, &movie.Slug, &imageURL, &bannerURL, 
-			&year, &description, &durationFormatted, &ageRatingFormatted,
-			&releaseDate, &movie.IsReleased, &movie.IsFamilyFriendly, &movie.IsShow,
-			&trailerVideoID, &movie.CountWatched, &movie.NumberOfSeasons,
-			&countriesStr, &languagesStr, &genresStr, &categoriesStr, &awards,
-			&actorsStr, &directorsStr, &movie.CreatedAt,
-		)
-		
+		movie, err := scanMovie(rows)
 		if err != nil {
 			continue
 		}
-		
-		// Convert sql.Null types to simple types
-		movie.ImageURL = models.NullStringToString(imageURL)
-		movie.BannerURL = models.NullStringToString(bannerURL)
-		movie.Year = models.NullInt64ToPtr(year)
-		movie.Description = models.NullStringToString(description)
-		movie.DurationFormatted = models.NullStringToString(durationFormatted)
-		movie.AgeRatingFormatted = models.NullStringToString(ageRatingFormatted)
-		movie.ReleaseDate = models.NullStringToString(releaseDate)
-		movie.TrailerVideoID = models.NullStringToString(trailerVideoID)
-		movie.Awards = models.NullStringToString(awards)
-		
-		// Parse JSON arrays of objects
-		movie.Countries = models.ParseCountries(countriesStr)
-		movie.Languages = models.ParseLanguages(languagesStr)
-		movie.Genres = models.ParseGenres(genresStr)
-		movie.Categories = models.ParseCategories(categoriesStr)
-		movie.Actors = models.ParsePeople(actorsStr)
-		movie.Directors = models.ParsePeople(directorsStr)
-		
 		movies = append(movies, movie)
 	}
 
+	if includesRating(c) && len(movies) > 0 {
+		slugs := make([]string, len(movies))
+		for i := range movies {
+			slugs[i] = movies[i].Slug
+		}
+		ratings := buildMovieRatings(slugs)
+		for i := range movies {
+			rating := ratings[movies[i].Slug]
+			movies[i].Rating = &rating
+		}
+	}
+
 	// Get total count
 	var total int
 	countQuery := "SELECT COUNT(*) FROM movies WHERE 1=1"
@@ -156,20 +134,7 @@ func GetMovieBySlug(c *gin.Context) {
 		WHERE slug = ?
 	`
 
-	var movie models.Movie
-	var imageURL, bannerURL, description, durationFormatted, ageRatingFormatted, 
-		releaseDate, trailerVideoID, awards, countriesStr, languagesStr, genresStr, 
-		categoriesStr, actorsStr, directorsStr sql.NullString
-	var year sql.NullInt64
-	
-	err := database.DB.QueryRow(query, slug).Scan(
-		&movie.Name, &movie.Slug, &imageURL, &bannerURL, 
-		&year, &description, &durationFormatted, &ageRatingFormatted,
-		&releaseDate, &movie.IsReleased, &movie.IsFamilyFriendly, &movie.IsShow,
-		&trailerVideoID, &movie.CountWatched, &movie.NumberOfSeasons,
-		&countriesStr, &languagesStr, &genresStr, &categoriesStr, &awards,
-		&actorsStr, &directorsStr, &movie.CreatedAt,
-	)
+	movie, err := scanMovie(database.DB.QueryRow(query, slug))
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -186,6 +151,46 @@ func GetMovieBySlug(c *gin.Context) {
 		return
 	}
 	
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data:    movie,
+	})
+}
+
+// movieColumns - Column list matching scanMovie
+const movieColumns = `
+			name, slug, image_url, banner_url, year, 
+			description, duration_formatted, age_rating_formatted,
+			release_date, is_released, is_family_friendly, is_show,
+			trailer_video_id, count_watched, number_of_seasons,
+			countries, languages, genres, categories, awards,
+			actors, directors, created_at`
+
+// rowScanner - Satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMovie - Scan a movies row selected with movieColumns
+func scanMovie(row rowScanner) (models.Movie, error) {
+	var movie models.Movie
+	var imageURL, bannerURL, description, durationFormatted, ageRatingFormatted, 
+		releaseDate, trailerVideoID, awards, countriesStr, languagesStr, genresStr, 
+		categoriesStr, actorsStr, directorsStr sql.NullString
+	var year sql.NullInt64
+
+	err := row.Scan(
+		&movie.Name, &movie.Slug, &imageURL, &bannerURL, 
+		&year, &description, &durationFormatted, &ageRatingFormatted,
+		&releaseDate, &movie.IsReleased, &movie.IsFamilyFriendly, &movie.IsShow,
+		&trailerVideoID, &movie.CountWatched, &movie.NumberOfSeasons,
+		&countriesStr, &languagesStr, &genresStr, &categoriesStr, &awards,
+		&actorsStr, &directorsStr, &movie.CreatedAt,
+	)
+	if err != nil {
+		return movie, err
+	}
+
 	// Convert sql.Null types to simple types
 	movie.ImageURL = models.NullStringToString(imageURL)
 	movie.BannerURL = models.NullStringToString(bannerURL)
@@ -196,7 +201,7 @@ func GetMovieBySlug(c *gin.Context) {
 	movie.ReleaseDate = models.NullStringToString(releaseDate)
 	movie.TrailerVideoID = models.NullStringToString(trailerVideoID)
 	movie.Awards = models.NullStringToString(awards)
-	
+
 	// Parse JSON arrays of objects
 	movie.Countries = models.ParseCountries(countriesStr)
 	movie.Languages = models.ParseLanguages(languagesStr)
@@ -205,8 +210,5 @@ func GetMovieBySlug(c *gin.Context) {
 	movie.Actors = models.ParsePeople(actorsStr)
 	movie.Directors = models.ParsePeople(directorsStr)
 
-	c.JSON(http.StatusOK, models.MovieResponse{
-		Success: true,
-		Data:    movie,
-	})
-}
\ No newline at end of file
+	return movie, nil
+}
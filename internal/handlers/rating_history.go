@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"movie-api/internal/database"
+	"movie-api/internal/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default and maximum ranges per granularity
+var historyRanges = map[string]struct{ defaultRange, maxRange time.Duration }{
+	database.GranularityHour: {48 * time.Hour, 31 * 24 * time.Hour},
+	database.GranularityDay:  {30 * 24 * time.Hour, 366 * 24 * time.Hour},
+}
+
+// GetMovieRatingHistory - Vote changes per hour/day for a sentiment chart
+func GetMovieRatingHistory(c *gin.Context) {
+	slug := c.Param("slug")
+	granularity := c.DefaultQuery("granularity", database.GranularityDay)
+
+	ranges, ok := historyRanges[granularity]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "granularity must be 'hour' or 'day'",
+		})
+		return
+	}
+
+	to := time.Now().UTC()
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := parseHistoryTime(toStr, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.MovieResponse{
+				Success: false,
+				Message: "Invalid 'to' (use RFC3339 or YYYY-MM-DD)",
+			})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-ranges.defaultRange)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := parseHistoryTime(fromStr, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.MovieResponse{
+				Success: false,
+				Message: "Invalid 'from' (use RFC3339 or YYYY-MM-DD)",
+			})
+			return
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "'from' must be before 'to'",
+		})
+		return
+	}
+	if to.Sub(from) > ranges.maxRange {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Requested range is too large for this granularity",
+		})
+		return
+	}
+
+	buckets, err := database.ResponseManagerInstance.GetVoteHistory(slug, granularity, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to fetch rating history",
+		})
+		return
+	}
+
+	history := models.RatingHistory{
+		MovieSlug:   slug,
+		Granularity: granularity,
+		From:        from,
+		To:          to,
+		Buckets:     make([]models.RatingHistoryBucket, 0, len(buckets)),
+	}
+	for _, bucket := range buckets {
+		history.Buckets = append(history.Buckets, models.RatingHistoryBucket{
+			BucketStart: bucket.Start,
+			Option0:     int64(bucket.Option0),
+			Option1:     int64(bucket.Option1),
+			Option2:     int64(bucket.Option2),
+			Option3:     int64(bucket.Option3),
+			TotalVotes:  int64(bucket.Total),
+		})
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data:    history,
+	})
+}
+
+// parseHistoryTime - RFC3339 or a bare date; a bare 'to' date covers the whole day
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
+}
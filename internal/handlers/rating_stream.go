@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"io"
+	"movie-api/internal/database"
+	"movie-api/internal/models"
+	"movie-api/internal/realtime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ratingStreamCoalesce  = 500 * time.Millisecond
+	ratingStreamHeartbeat = 15 * time.Second
+)
+
+var ratingHub *realtime.RatingHub
+
+// InitRatingStream - Create the rating hub and hook it to the vote flusher
+func InitRatingStream() *realtime.RatingHub {
+	ratingHub = realtime.NewRatingHub(func(slug string) interface{} {
+		return buildMovieRating(slug)
+	}, ratingStreamCoalesce)
+
+	database.ResponseManagerInstance.OnFlush(ratingHub.Notify)
+	return ratingHub
+}
+
+// StreamMovieRating - Server-Sent Events feed of rating updates for a movie
+func StreamMovieRating(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Slug parameter is required",
+		})
+		return
+	}
+
+	sub := ratingHub.Subscribe(slug)
+	defer ratingHub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+
+	// Current state first so the client doesn't wait for the next vote
+	c.SSEvent("rating", buildMovieRating(slug))
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(ratingStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case rating, ok := <-sub.Updates:
+			if !ok {
+				// Hub closed (server shutting down)
+				return false
+			}
+			c.SSEvent("rating", rating)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
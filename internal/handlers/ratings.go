@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"movie-api/internal/auth"
 	"movie-api/internal/database"
 	"movie-api/internal/models"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,9 +25,32 @@ func GetMovieRating(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data:    buildMovieRating(slug),
+	})
+}
+
+// buildMovieRating - Rating payload shared by the REST and stream endpoints
+func buildMovieRating(slug string) models.MovieRating {
 	// USE RESPONSE MANAGER INSTEAD OF DB QUERY
 	counts := database.ResponseManagerInstance.GetMovieCounts(slug)
-	
+	return ratingFromCounts(slug, *counts, ratingPrior())
+}
+
+// buildMovieRatings - buildMovieRating for many slugs with one count lookup
+func buildMovieRatings(slugs []string) map[string]models.MovieRating {
+	counts := database.ResponseManagerInstance.GetMovieCountsFor(slugs)
+	prior := ratingPrior()
+
+	ratings := make(map[string]models.MovieRating, len(slugs))
+	for _, slug := range slugs {
+		ratings[slug] = ratingFromCounts(slug, counts[slug], prior)
+	}
+	return ratings
+}
+
+func ratingFromCounts(slug string, counts database.MovieResponseCounts, prior models.RatingPrior) models.MovieRating {
 	rating := models.MovieRating{
 		MovieSlug:   slug,
 		Option0:     int64(counts.Option0),
@@ -34,11 +60,14 @@ func GetMovieRating(c *gin.Context) {
 		TotalVotes:  int64(counts.Total),
 		UpdatedAt:   time.Now(),
 	}
+	rating.ComputeScores(prior)
+	return rating
+}
 
-	c.JSON(http.StatusOK, models.MovieResponse{
-		Success: true,
-		Data:    rating,
-	})
+// ratingPrior - Bayesian prior from the site-wide vote totals
+func ratingPrior() models.RatingPrior {
+	totals := database.ResponseManagerInstance.GetGlobalVoteTotals()
+	return models.NewRatingPrior(totals.Option0, totals.Option1, totals.Option2, totals.Option3, totals.MoviesWithVotes)
 }
 
 // GetUserVoteStatus - Check if user has voted for a movie
@@ -97,6 +126,25 @@ func SubmitVote(c *gin.Context) {
 		return
 	}
 
+	// Only released movies we know about can be voted on
+	movieExists, isReleased := database.MovieCatalogInstance.Lookup(request.MovieSlug)
+	if !movieExists {
+		c.JSON(http.StatusNotFound, models.MovieResponse{
+			Success: false,
+			Message: "Movie not found",
+			Code:    "MOVIE_NOT_FOUND",
+		})
+		return
+	}
+	if !isReleased {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Voting opens once the movie is released",
+			Code:    "MOVIE_NOT_RELEASED",
+		})
+		return
+	}
+
 	// Get user payload from context
 	userPayload, exists := c.Get("user_payload")
 	if !exists {
@@ -113,11 +161,201 @@ func SubmitVote(c *gin.Context) {
 
 	// Add to in-memory buffer - dereference the pointer
 	//for testing without auth
-	database.ResponseManagerInstance.AddResponse(payload.UserID, request.MovieSlug, *request.OptionChosen)
-	// database.ResponseManagerInstance.AddResponse(userId, request.MovieSlug, *request.OptionChosen)
+	source := database.VoteSource{
+		ClientIP:       c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		TokenCreatedAt: payload.CreatedAt,
+	}
+	err := database.ResponseManagerInstance.AddResponse(payload.UserID, request.MovieSlug, *request.OptionChosen, source)
+	// err := database.ResponseManagerInstance.AddResponse(userId, request.MovieSlug, *request.OptionChosen, source)
+	if err != nil {
+		respondVoteRejected(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, models.MovieResponse{
 		Success: true,
 		Message: "Vote submitted successfully",
 	})
-}
\ No newline at end of file
+}
+
+// RetractVote - Take back the user's vote for a movie
+func RetractVote(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Slug parameter is required",
+		})
+		return
+	}
+
+	userPayload, exists := c.Get("user_payload")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.MovieResponse{
+			Success: false,
+			Message: "User token required",
+		})
+		return
+	}
+	payload := userPayload.(*auth.TokenPayload)
+
+	// Goes through the same queue so it lands after any pending vote from this user
+	if err := database.ResponseManagerInstance.AddRetraction(payload.UserID, slug); err != nil {
+		respondVoteRejected(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Message: "Vote retracted successfully",
+	})
+}
+
+// respondVoteRejected - Map vote buffer errors to backpressure responses
+func respondVoteRejected(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, database.ErrVoteBufferFull):
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusTooManyRequests, models.MovieResponse{
+			Success: false,
+			Message: "Too many votes right now, please retry shortly",
+			Code:    "VOTE_BUFFER_FULL",
+		})
+	case errors.Is(err, database.ErrVoteManagerInactive):
+		c.Header("Retry-After", "5")
+		c.JSON(http.StatusServiceUnavailable, models.MovieResponse{
+			Success: false,
+			Message: "Voting is temporarily unavailable",
+			Code:    "VOTE_SERVICE_UNAVAILABLE",
+		})
+	default:
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusServiceUnavailable, models.MovieResponse{
+			Success: false,
+			Message: "Vote could not be saved, please retry",
+			Code:    "VOTE_NOT_PERSISTED",
+		})
+	}
+}
+
+// maxVoteStatusSlugs - Enough for the largest listing page
+const maxVoteStatusSlugs = 100
+
+// GetUserVoteStatuses - The user's choice for each slug in one request (null when not voted)
+func GetUserVoteStatuses(c *gin.Context) {
+	var request struct {
+		MovieSlugs []string `json:"movie_slugs" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	slugs := make([]string, 0, len(request.MovieSlugs))
+	seen := make(map[string]bool, len(request.MovieSlugs))
+	for _, slug := range request.MovieSlugs {
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+	if len(slugs) > maxVoteStatusSlugs {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: fmt.Sprintf("At most %d movie slugs per request", maxVoteStatusSlugs),
+			Code:    "TOO_MANY_SLUGS",
+		})
+		return
+	}
+
+	choices := make(map[string]interface{}, len(slugs))
+	for _, slug := range slugs {
+		choices[slug] = nil
+	}
+
+	userPayload, exists := c.Get("user_payload")
+	if !exists {
+		c.JSON(http.StatusOK, models.MovieResponse{
+			Success: true,
+			Data:    map[string]interface{}{"votes": choices},
+		})
+		return
+	}
+	payload := userPayload.(*auth.TokenPayload)
+
+	voted, err := database.ResponseManagerInstance.UserVoteChoices(payload.UserID, slugs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to fetch vote status",
+		})
+		return
+	}
+	for slug, choice := range voted {
+		choices[slug] = choice
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data:    map[string]interface{}{"votes": choices},
+	})
+}
+
+// maxRatingSlugs - Upper bound for GET /api/ratings?slugs=
+const maxRatingSlugs = 100
+
+// GetMovieRatings - Ratings for a comma-separated list of slugs in one request
+func GetMovieRatings(c *gin.Context) {
+	slugs := parseSlugList(c.Query("slugs"))
+	if len(slugs) == 0 {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "slugs parameter is required",
+		})
+		return
+	}
+	if len(slugs) > maxRatingSlugs {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: fmt.Sprintf("At most %d movie slugs per request", maxRatingSlugs),
+			Code:    "TOO_MANY_SLUGS",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data:    map[string]interface{}{"ratings": buildMovieRatings(slugs)},
+	})
+}
+
+// parseSlugList - Split "a,b,c", dropping blanks and duplicates
+func parseSlugList(raw string) []string {
+	var slugs []string
+	seen := make(map[string]bool)
+	for _, slug := range strings.Split(raw, ",") {
+		slug = strings.TrimSpace(slug)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+	return slugs
+}
+
+// includesRating - Whether ?include= asks for ratings to be embedded
+func includesRating(c *gin.Context) bool {
+	for _, field := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(field) == "rating" {
+			return true
+		}
+	}
+	return false
+}
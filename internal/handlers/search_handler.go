@@ -60,10 +60,11 @@ func SearchMovies(c *gin.Context) {
 	defer rows.Close()
 
 	type SimpleMovie struct {
-		Name     string `json:"name"`
-		Slug     string `json:"slug"`
-		ImageURL string `json:"image_url"`
-		Year     *int64 `json:"year,omitempty"`
+		Name     string              `json:"name"`
+		Slug     string              `json:"slug"`
+		ImageURL string              `json:"image_url"`
+		Year     *int64              `json:"year,omitempty"`
+		Rating   *models.MovieRating `json:"rating,omitempty"` // Only with ?include=rating
 	}
 
 	var movies []SimpleMovie
@@ -85,6 +86,18 @@ func SearchMovies(c *gin.Context) {
 		movies = append(movies, movie)
 	}
 
+	if includesRating(c) && len(movies) > 0 {
+		slugs := make([]string, len(movies))
+		for i := range movies {
+			slugs[i] = movies[i].Slug
+		}
+		ratings := buildMovieRatings(slugs)
+		for i := range movies {
+			rating := ratings[movies[i].Slug]
+			movies[i].Rating = &rating
+		}
+	}
+
 	// Get total count
 	var total int
 	countQuery := "SELECT COUNT(*) FROM movies WHERE name LIKE ?"
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"log"
+	"movie-api/internal/database"
+	"movie-api/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrendingMovies - Movies ranked by recent vote velocity (same shape as GetMovies)
+func GetTrendingMovies(c *gin.Context) {
+	window := c.DefaultQuery("window", "24h")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	isShowStr := c.Query("is_show")
+
+	if _, ok := database.TrendingWindows[window]; !ok {
+		c.JSON(http.StatusBadRequest, models.MovieResponse{
+			Success: false,
+			Message: "window must be '24h' or '7d'",
+		})
+		return
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var isShow *bool
+	if isShowStr != "" {
+		if parsed, err := strconv.ParseBool(isShowStr); err == nil {
+			isShow = &parsed
+		}
+	}
+
+	movies, err := loadTrendingMovies(window, isShow)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to fetch trending movies",
+		})
+		return
+	}
+
+	total := len(movies)
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	response := map[string]interface{}{
+		"movies": movies[start:end],
+		"pagination": models.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	}
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+// loadTrendingMovies - Ranked movies with details, in ranking order
+func loadTrendingMovies(window string, isShow *bool) ([]models.Movie, error) {
+	ranked := database.TrendingRankerInstance.Ranked(window)
+	if len(ranked) == 0 {
+		return []models.Movie{}, nil
+	}
+
+	placeholders := make([]string, len(ranked))
+	args := make([]interface{}, 0, len(ranked)+1)
+	for i, movie := range ranked {
+		placeholders[i] = "?"
+		args = append(args, movie.Slug)
+	}
+
+	query := "SELECT " + movieColumns + " FROM movies WHERE slug IN (" + strings.Join(placeholders, ",") + ")"
+	if isShow != nil {
+		query += " AND is_show = ?"
+		args = append(args, *isShow)
+	}
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bySlug := make(map[string]models.Movie, len(ranked))
+	for rows.Next() {
+		movie, err := scanMovie(rows)
+		if err != nil {
+			continue
+		}
+		bySlug[movie.Slug] = movie
+	}
+
+	movies := make([]models.Movie, 0, len(bySlug))
+	for _, rankedMovie := range ranked {
+		if movie, ok := bySlug[rankedMovie.Slug]; ok {
+			movies = append(movies, movie)
+		}
+	}
+	return movies, nil
+}
+
+// trendingSectionItems - Computed items for the trending_movies homepage section
+func trendingSectionItems(limit int) []models.SectionItem {
+	movies, err := loadTrendingMovies("24h", nil)
+	if err != nil {
+		log.Printf("⚠️ Failed to load trending movies for homepage: %v", err)
+		return nil
+	}
+	if len(movies) > limit {
+		movies = movies[:limit]
+	}
+
+	items := make([]models.SectionItem, 0, len(movies))
+	for _, movie := range movies {
+		items = append(items, models.SectionItem{
+			Slug:      movie.Slug,
+			Name:      movie.Name,
+			PosterURL: movie.ImageURL,
+		})
+	}
+	return items
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"database/sql"
+	"movie-api/internal/auth"
+	"movie-api/internal/database"
+	"movie-api/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserVotes - Every movie the current token has voted on, newest first
+func GetUserVotes(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	votes := []models.UserVote{}
+	pagination := models.Pagination{Page: page, Limit: limit}
+
+	userPayload, exists := c.Get("user_payload")
+	if !exists {
+		c.JSON(http.StatusOK, models.MovieResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"votes":      votes,
+				"pagination": pagination,
+			},
+		})
+		return
+	}
+	payload := userPayload.(*auth.TokenPayload)
+
+	// Queued votes lead the list; the store serves the rest, so this works with any VoteStore
+	records, total, err := database.ResponseManagerInstance.UserVotes(payload.UserID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to fetch votes",
+		})
+		return
+	}
+
+	votes, err = userVoteCards(records)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.MovieResponse{
+			Success: false,
+			Message: "Failed to fetch votes",
+		})
+		return
+	}
+	pagination.Total = total
+
+	c.JSON(http.StatusOK, models.MovieResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"votes":      votes,
+			"pagination": pagination,
+		},
+	})
+}
+
+// userVoteCards - Add the movie info for each vote, keeping their order.
+// Votes for movies that were since deleted are left out.
+func userVoteCards(records []database.UserVoteRecord) ([]models.UserVote, error) {
+	votes := []models.UserVote{}
+	if len(records) == 0 {
+		return votes, nil
+	}
+
+	placeholders := make([]string, len(records))
+	args := make([]interface{}, len(records))
+	for i, record := range records {
+		placeholders[i] = "?"
+		args[i] = record.MovieSlug
+	}
+	rows, err := database.DB.Query(`
+		SELECT slug, name, image_url, is_show FROM movies
+		WHERE slug IN (`+strings.Join(placeholders, ",")+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movies := make(map[string]models.UserVote, len(records))
+	for rows.Next() {
+		var movie models.UserVote
+		var imageURL sql.NullString
+		if err := rows.Scan(&movie.MovieSlug, &movie.Name, &imageURL, &movie.IsShow); err != nil {
+			continue
+		}
+		movie.ImageURL = models.NullStringToString(imageURL)
+		movies[movie.MovieSlug] = movie
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		vote, ok := movies[record.MovieSlug]
+		if !ok {
+			continue
+		}
+		vote.OptionChosen = record.OptionChosen
+		if record.VotedAt > 0 {
+			t := time.Unix(record.VotedAt, 0).UTC()
+			vote.VotedAt = &t
+		}
+		votes = append(votes, vote)
+	}
+	return votes, nil
+}
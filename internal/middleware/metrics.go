@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "mocktale_http_request_duration_seconds",
+	Help:    "HTTP request latency by route group, method and status class.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"group", "method", "status"})
+
+func init() {
+	prometheus.MustRegister(httpRequestDuration)
+}
+
+// RequestMetrics - Time every request into httpRequestDuration
+func RequestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status()/100) + "xx"
+		httpRequestDuration.WithLabelValues(routeGroup(c.FullPath()), c.Request.Method, status).Observe(time.Since(started).Seconds())
+	}
+}
+
+// routeGroup - First two path segments of the matched route, so label cardinality stays fixed
+func routeGroup(fullPath string) string {
+	if fullPath == "" {
+		return "unmatched"
+	}
+	segments := strings.Split(strings.Trim(fullPath, "/"), "/")
+
+	// Streams stay open for minutes and would swamp the group's latency
+	if segments[len(segments)-1] == "stream" {
+		return "stream"
+	}
+	if len(segments) > 2 {
+		segments = segments[:2]
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,53 @@
+// middleware/rate_limit.go
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"movie-api/internal/auth"
+	"movie-api/internal/ratelimit"
+	"movie-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VoteRateLimit - Token bucket per client IP and per user token for vote routes
+func VoteRateLimit() gin.HandlerFunc {
+	// Per-IP limit is higher because many voters can share one NAT address
+	perIP := ratelimit.New(utils.GetEnvInt("VOTE_RATE_LIMIT_PER_IP", 300), time.Minute, utils.GetEnvInt("VOTE_RATE_BURST_PER_IP", 60))
+	perToken := ratelimit.New(utils.GetEnvInt("VOTE_RATE_LIMIT_PER_TOKEN", 30), time.Minute, utils.GetEnvInt("VOTE_RATE_BURST_PER_TOKEN", 10))
+
+	return func(c *gin.Context) {
+		if allowed, wait := perIP.Allow(c.ClientIP()); !allowed {
+			RejectRateLimited(c, wait)
+			return
+		}
+
+		if userPayload, exists := c.Get("user_payload"); exists {
+			payload := userPayload.(*auth.TokenPayload)
+			if allowed, wait := perToken.Allow(payload.UserID); !allowed {
+				RejectRateLimited(c, wait)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RejectRateLimited - 429 with Retry-After rounded up to whole seconds
+func RejectRateLimited(c *gin.Context, wait time.Duration) {
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"success": false,
+		"message": "Too many requests, please slow down",
+		"code":    "RATE_LIMITED",
+	})
+	c.Abort()
+}
@@ -65,6 +65,7 @@ type Movie struct {
 	Actors              []Person  `json:"actors"`
 	Directors           []Person  `json:"directors"`
 	CreatedAt           time.Time `json:"created_at"`
+	Rating              *MovieRating `json:"rating,omitempty"` // Only with ?include=rating
 }
 
 type MovieRating struct {
@@ -75,12 +76,19 @@ type MovieRating struct {
 	Option3     int64     `json:"perfect_reviews"`
 	TotalVotes  int64     `json:"total_votes"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Computed from the counts above (see ComputeScores)
+	AverageScore  *float64          `json:"average_score"`  // 0-10, nil when there are no votes
+	BayesianScore float64           `json:"bayesian_score"` // 0-10, adjusted toward the global mean
+	Percentages   RatingPercentages `json:"percentages"`
+	Confidence    string            `json:"confidence"` // low / medium / high
 }
 
 type MovieResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data"`
 	Message string      `json:"message,omitempty"`
+	Code    string      `json:"code,omitempty"` // Machine-readable error code
 }
 
 type Pagination struct {
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Score (0-10) for each vote option: negative, neutral, positive, perfect
+var optionScores = [4]float64{0, 10.0 / 3, 20.0 / 3, 10}
+
+// Vote counts below these are flagged as low / medium confidence
+const (
+	lowConfidenceVotes    = 10
+	mediumConfidenceVotes = 50
+)
+
+type RatingPercentages struct {
+	Negative float64 `json:"negative"`
+	Neutral  float64 `json:"neutral"`
+	Positive float64 `json:"positive"`
+	Perfect  float64 `json:"perfect"`
+}
+
+// RatingPrior - Site-wide average used to pull low-vote movies toward the mean
+type RatingPrior struct {
+	MeanScore float64 // Average score across every vote
+	Weight    float64 // How many "virtual" votes the prior is worth
+}
+
+// NewRatingPrior - Prior from vote totals summed over all movies
+func NewRatingPrior(option0, option1, option2, option3, moviesWithVotes int64) RatingPrior {
+	total := option0 + option1 + option2 + option3
+	if total == 0 || moviesWithVotes == 0 {
+		// No votes anywhere yet - centre of the scale
+		return RatingPrior{MeanScore: 5, Weight: lowConfidenceVotes}
+	}
+
+	return RatingPrior{
+		MeanScore: weightedScore(option0, option1, option2, option3),
+		// Average votes per movie, so a typical movie weighs as much as the prior
+		Weight: math.Max(1, float64(total)/float64(moviesWithVotes)),
+	}
+}
+
+// ComputeScores - Fill the derived fields from the raw option counts
+func (r *MovieRating) ComputeScores(prior RatingPrior) {
+	total := r.Option0 + r.Option1 + r.Option2 + r.Option3
+
+	if total > 0 {
+		average := round(weightedScore(r.Option0, r.Option1, r.Option2, r.Option3), 2)
+		r.AverageScore = &average
+		r.Percentages = RatingPercentages{
+			Negative: percentage(r.Option0, total),
+			Neutral:  percentage(r.Option1, total),
+			Positive: percentage(r.Option2, total),
+			Perfect:  percentage(r.Option3, total),
+		}
+	} else {
+		// No votes isn't the same as every vote negative
+		r.AverageScore = nil
+		r.Percentages = RatingPercentages{}
+	}
+
+	scoreSum := float64(r.Option0)*optionScores[0] + float64(r.Option1)*optionScores[1] +
+		float64(r.Option2)*optionScores[2] + float64(r.Option3)*optionScores[3]
+	r.BayesianScore = round((prior.Weight*prior.MeanScore+scoreSum)/(prior.Weight+float64(total)), 2)
+
+	switch {
+	case total < lowConfidenceVotes:
+		r.Confidence = "low"
+	case total < mediumConfidenceVotes:
+		r.Confidence = "medium"
+	default:
+		r.Confidence = "high"
+	}
+}
+
+func weightedScore(option0, option1, option2, option3 int64) float64 {
+	total := option0 + option1 + option2 + option3
+	if total == 0 {
+		return 0
+	}
+	sum := float64(option0)*optionScores[0] + float64(option1)*optionScores[1] +
+		float64(option2)*optionScores[2] + float64(option3)*optionScores[3]
+	return sum / float64(total)
+}
+
+func percentage(count, total int64) float64 {
+	return round(float64(count)*100/float64(total), 1)
+}
+
+func round(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
+
+// RatingHistoryBucket - Net vote changes for one hour or day
+type RatingHistoryBucket struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Option0     int64     `json:"negative_reviews"`
+	Option1     int64     `json:"neutral_reviews"`
+	Option2     int64     `json:"positive_reviews"`
+	Option3     int64     `json:"perfect_reviews"`
+	TotalVotes  int64     `json:"total_votes"`
+}
+
+type RatingHistory struct {
+	MovieSlug   string                `json:"movie_slug"`
+	Granularity string                `json:"granularity"`
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	Buckets     []RatingHistoryBucket `json:"buckets"`
+}
+
+// UserVote - One entry in a user's rating history, with enough movie info for a poster card
+type UserVote struct {
+	MovieSlug    string     `json:"movie_slug"`
+	Name         string     `json:"name"`
+	ImageURL     string     `json:"image_url,omitempty"`
+	IsShow       bool       `json:"is_show"`
+	OptionChosen int        `json:"option_chosen"`
+	VotedAt      *time.Time `json:"voted_at"` // nil for votes cast before vote times were recorded
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	shardCount = 32
+	// Buckets untouched this long are full again and can be dropped
+	idleSweepInterval = time.Minute
+)
+
+// Limiter - Token bucket per key (user token, client IP, ...)
+type Limiter struct {
+	rate   float64 // tokens added per second
+	burst  float64
+	shards [shardCount]*limiterShard
+}
+
+type limiterShard struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New - Allow `events` per `per` for each key, with bursts up to burst
+func New(events int, per time.Duration, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &Limiter{
+		rate:  float64(events) / per.Seconds(),
+		burst: float64(burst),
+	}
+	for i := range l.shards {
+		l.shards[i] = &limiterShard{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+	}
+	return l
+}
+
+// Allow - Take one token for key; when refused, also returns how long until the next token
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	shard := l.shards[h.Sum32()%shardCount]
+
+	now := time.Now()
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if now.Sub(shard.lastSweep) > idleSweepInterval {
+		l.sweep(shard, now)
+	}
+
+	b, ok := shard.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		shard.buckets[key] = b
+	} else {
+		b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep - Drop buckets that have refilled completely (caller holds shard.mu)
+func (l *Limiter) sweep(shard *limiterShard, now time.Time) {
+	refillTime := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range shard.buckets {
+		if now.Sub(b.last) > refillTime {
+			delete(shard.buckets, key)
+		}
+	}
+	shard.lastSweep = now
+}
@@ -0,0 +1,146 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+)
+
+// RatingHub - Fans out rating updates to stream subscribers, coalesced per slug
+type RatingHub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*Subscriber]struct{}
+	dirty       map[string]struct{}
+	load        func(slug string) interface{}
+	interval    time.Duration
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// Subscriber - One client stream; Updates holds at most the latest payload
+type Subscriber struct {
+	slug    string
+	Updates chan interface{}
+}
+
+// NewRatingHub - load builds the payload sent to subscribers of a slug
+func NewRatingHub(load func(slug string) interface{}, interval time.Duration) *RatingHub {
+	hub := &RatingHub{
+		subscribers: make(map[string]map[*Subscriber]struct{}),
+		dirty:       make(map[string]struct{}),
+		load:        load,
+		interval:    interval,
+		done:        make(chan struct{}),
+	}
+	go hub.run()
+	return hub
+}
+
+// Subscribe - Register a stream for a slug
+func (h *RatingHub) Subscribe(slug string) *Subscriber {
+	sub := &Subscriber{slug: slug, Updates: make(chan interface{}, 1)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		close(sub.Updates)
+		return sub
+	default:
+	}
+
+	if h.subscribers[slug] == nil {
+		h.subscribers[slug] = make(map[*Subscriber]struct{})
+	}
+	h.subscribers[slug][sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe - Remove a stream after the client disconnects
+func (h *RatingHub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subscribers[sub.slug]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.Updates)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.slug)
+	}
+}
+
+// Notify - Mark slugs as changed; only watched slugs are kept
+func (h *RatingHub) Notify(slugs []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, slug := range slugs {
+		if _, watched := h.subscribers[slug]; watched {
+			h.dirty[slug] = struct{}{}
+		}
+	}
+}
+
+// Close - Stop the hub and end every open stream
+func (h *RatingHub) Close() {
+	h.closeOnce.Do(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		close(h.done)
+		for slug, subs := range h.subscribers {
+			for sub := range subs {
+				close(sub.Updates)
+			}
+			delete(h.subscribers, slug)
+		}
+	})
+}
+
+// run - Publish dirty slugs once per interval
+func (h *RatingHub) run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.publish()
+		case <-h.done:
+			return
+		}
+	}
+}
+
+func (h *RatingHub) publish() {
+	h.mu.Lock()
+	if len(h.dirty) == 0 {
+		h.mu.Unlock()
+		return
+	}
+	dirty := h.dirty
+	h.dirty = make(map[string]struct{})
+	h.mu.Unlock()
+
+	for slug := range dirty {
+		// Built once per slug, outside the lock
+		payload := h.load(slug)
+
+		h.mu.Lock()
+		for sub := range h.subscribers[slug] {
+			// Replace any payload the client has not picked up yet
+			select {
+			case <-sub.Updates:
+			default:
+			}
+			sub.Updates <- payload
+		}
+		h.mu.Unlock()
+	}
+}
@@ -20,5 +20,23 @@ func SetupAdminRoutes(router *gin.Engine) {
 		admin.GET("/homepage", handlers.AdminGetHomepageSections)        // Get all sections
 		admin.PUT("/homepage", handlers.AdminUpdateHomepage)             // Update entire homepage
 		admin.POST("/homepage/reset", handlers.AdminResetHomepage)       // Reset to default
+
+		// Vote pipeline monitoring
+		admin.GET("/votes/stats", handlers.AdminGetVoteStats)
+
+		// Abuse review queue
+		admin.GET("/votes/quarantine", handlers.AdminListQuarantinedVotes)
+		admin.POST("/votes/quarantine/approve", handlers.AdminApproveQuarantinedVotes)
+		admin.POST("/votes/quarantine/discard", handlers.AdminDiscardQuarantinedVotes)
+
+		// Votes that failed to flush
+		admin.GET("/votes/dead-letters", handlers.AdminListDeadLetters)
+		admin.POST("/votes/dead-letters/replay", handlers.AdminReplayDeadLetters)
+
+		// Ratings maintenance
+		admin.POST("/ratings/rebuild", handlers.AdminRebuildRatings)
+
+		// Abuse response - revoke a user token and optionally its votes
+		admin.POST("/tokens/revoke", handlers.AdminRevokeToken)
 	}
 }
\ No newline at end of file
@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"log"
 	"movie-api/internal/auth"
 	"movie-api/internal/handlers"
+	"movie-api/internal/middleware"
+	"net/http"
 	"strings"
 	"time"
 
@@ -10,10 +13,24 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func SetupRoutes() *gin.Engine {
 	router := gin.Default()
+	router.Use(middleware.RequestMetrics())
+
+	// Without a separate METRICS_ADDR listener, scrapes need the admin key.
+	// Registered before the token middleware so scrapes don't mint user tokens
+	if os.Getenv("METRICS_ADDR") == "" {
+		router.GET("/metrics", middleware.AdminAuth(), gin.WrapH(promhttp.Handler()))
+	}
+
+	// Only trust X-Forwarded-For from our own proxy, otherwise per-IP limits are spoofable
+	trustedProxies := getEnv("TRUSTED_PROXIES", "127.0.0.1,::1")
+	if err := router.SetTrustedProxies(strings.Split(trustedProxies, ",")); err != nil {
+		log.Printf("⚠️ Invalid TRUSTED_PROXIES %q: %v", trustedProxies, err)
+	}
 
 	allowedOrigins := getEnv("ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:5174,http://127.0.0.1:5173")
 	originsList := strings.Split(allowedOrigins, ",")
@@ -28,9 +45,6 @@ func SetupRoutes() *gin.Engine {
 		MaxAge:           12 * time.Hour,
 	}))
 
-	// Token middleware for user routes
-	router.Use(auth.GetOrCreateToken())
-
 	// API routes
 	api := router.Group("/api")
 	{
@@ -43,20 +57,28 @@ func SetupRoutes() *gin.Engine {
 		movies := api.Group("/movies")
 		{
 			movies.GET("", handlers.GetMovies)
+			movies.GET("/trending", handlers.GetTrendingMovies)
 			movies.GET("/:slug", handlers.GetMovieBySlug)
 		}
 
 		// Public rating routes
 		ratings := api.Group("/ratings")
 		{
+			ratings.GET("", handlers.GetMovieRatings)
 			ratings.GET("/:slug", handlers.GetMovieRating)
+			ratings.GET("/:slug/stream", handlers.StreamMovieRating)
+			ratings.GET("/:slug/history", handlers.GetMovieRatingHistory)
 		}
 
 		// User routes (require token)
-		user := api.Group("/user")
+		user := api.Group("/user", auth.GetOrCreateToken())
 		{
+			voteRateLimit := middleware.VoteRateLimit()
 			user.GET("/vote-status/:slug", handlers.GetUserVoteStatus)
-			user.POST("/vote", handlers.SubmitVote)
+			user.POST("/vote-status", handlers.GetUserVoteStatuses)
+			user.GET("/votes", handlers.GetUserVotes)
+			user.POST("/vote", voteRateLimit, handlers.SubmitVote)
+			user.DELETE("/vote/:slug", voteRateLimit, handlers.RetractVote)
 			user.GET("/token", handlers.GetOrCreateToken)
 		}
 
@@ -79,6 +101,17 @@ func SetupRoutes() *gin.Engine {
 	return router
 }
 
+// MetricsServer - /metrics on its own listener when METRICS_ADDR is set (e.g. 127.0.0.1:9090), nil otherwise
+func MetricsServer() *http.Server {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return &http.Server{Addr: addr, Handler: mux}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
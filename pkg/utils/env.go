@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetEnvInt - Integer env var, or defaultValue when unset or unparseable (logged)
+func GetEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		log.Printf("⚠️ Invalid integer for %s: %q, using %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// GetEnvDuration - Duration env var (e.g. "500ms"), or defaultValue when unset or unparseable (logged)
+func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		log.Printf("⚠️ Invalid duration for %s: %q, using %s", key, value, defaultValue)
+	}
+	return defaultValue
+}